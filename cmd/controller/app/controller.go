@@ -21,12 +21,16 @@ import (
 	"github.com/itscontained/secret-manager/cmd/controller/app/options"
 	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
 	esctrl "github.com/itscontained/secret-manager/pkg/controller/externalsecret"
+	"github.com/itscontained/secret-manager/pkg/controller/index"
+	ssctrl "github.com/itscontained/secret-manager/pkg/controller/secretstore"
 	"github.com/itscontained/secret-manager/pkg/util"
+	"github.com/itscontained/secret-manager/pkg/util/serviceaccount"
 
 	"github.com/spf13/cobra"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	// kubernetes import to support cloud provider auth
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
@@ -80,16 +84,70 @@ func NewController(opts *options.ControllerOptions) (*Controller, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the controller-runtime client cannot request ServiceAccount tokens
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	kubeClient := serviceaccount.NewClient(c.manager.GetClient(), clientset.CoreV1())
+
+	if err = index.SetupStoreSecretRefs(c.manager.GetFieldIndexer(), c.options.Namespace); err != nil {
+		log.Errorf("Unable to create store index: %v", err.Error())
+		return nil, err
+	}
+
 	if err = (&esctrl.ExternalSecretReconciler{
-		Client: c.manager.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ExternalSecret"),
-		Scheme: c.manager.GetScheme(),
-		Reader: c.manager.GetAPIReader(),
+		Client:    kubeClient,
+		Log:       ctrl.Log.WithName("controllers").WithName("ExternalSecret"),
+		Scheme:    c.manager.GetScheme(),
+		Reader:    c.manager.GetAPIReader(),
+		Recorder:  c.manager.GetEventRecorderFor("secret-manager"),
+		Namespace: c.options.Namespace,
+
+		DefaultRefreshInterval: c.options.DefaultRefreshInterval,
+		BackoffBase:            c.options.SyncBackoffBase,
+		BackoffMax:             c.options.SyncBackoffMax,
 	}).SetupWithManager(c.manager); err != nil {
 		log.Errorf("Unable to create ExternalSecret controller: %v", err.Error())
 		return nil, err
 	}
 
+	storeKinds := []string{smv1alpha1.SecretStoreKind}
+	if c.options.Namespace == "" {
+		storeKinds = append(storeKinds, smv1alpha1.ClusterSecretStoreKind)
+	}
+	for _, kind := range storeKinds {
+		if err = (&ssctrl.SecretStoreReconciler{
+			Client:   kubeClient,
+			Log:      ctrl.Log.WithName("controllers").WithName(kind),
+			Scheme:   c.manager.GetScheme(),
+			Recorder: c.manager.GetEventRecorderFor("secret-manager"),
+			Kind:     kind,
+
+			CheckInterval: c.options.StoreCheckInterval,
+		}).SetupWithManager(c.manager); err != nil {
+			log.Errorf("Unable to create %s controller: %v", kind, err.Error())
+			return nil, err
+		}
+	}
+
+	if c.options.EnableWebhooks {
+		if err = (&smv1alpha1.ExternalSecret{}).SetupWebhookWithManager(c.manager); err != nil {
+			log.Errorf("Unable to create ExternalSecret webhook: %v", err.Error())
+			return nil, err
+		}
+		if err = (&smv1alpha1.SecretStore{}).SetupWebhookWithManager(c.manager); err != nil {
+			log.Errorf("Unable to create SecretStore webhook: %v", err.Error())
+			return nil, err
+		}
+		if c.options.Namespace == "" {
+			if err = (&smv1alpha1.ClusterSecretStore{}).SetupWebhookWithManager(c.manager); err != nil {
+				log.Errorf("Unable to create ClusterSecretStore webhook: %v", err.Error())
+				return nil, err
+			}
+		}
+	}
+
 	err = c.manager.AddReadyzCheck("ready-ping", healthz.Ping)
 	if err != nil {
 		log.Errorf("Unable add a readiness check to controller: %v", err.Error())
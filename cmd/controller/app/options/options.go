@@ -15,6 +15,7 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -33,6 +34,13 @@ type ControllerOptions struct {
 
 	EnabledControllers []string
 
+	DefaultRefreshInterval time.Duration
+	SyncBackoffBase        time.Duration
+	SyncBackoffMax         time.Duration
+	StoreCheckInterval     time.Duration
+
+	EnableWebhooks bool
+
 	WebhookPort int
 	HealthPort  int
 	MetricPort  int
@@ -76,6 +84,25 @@ func (s *ControllerOptions) InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&s.LeaderElectionRetryPeriod, "leader-election-retry-period", 5*time.Second,
 		"The duration the clients should wait between attempting acquisition and renewal "+
 			"of a leadership. This is only applicable if leader election is enabled.")
+	fs.DurationVar(&s.DefaultRefreshInterval, "default-refresh-interval", time.Hour,
+		"The interval after which ExternalSecrets are re-synced with their store if "+
+			"they do not specify a refresh interval. A value of 0 disables periodic refresh.")
+	fs.DurationVar(&s.SyncBackoffBase, "sync-backoff-base", 5*time.Second,
+		"The delay before retrying the first failed sync of an ExternalSecret. The delay "+
+			"doubles with every consecutive failure, with added jitter.")
+	fs.DurationVar(&s.SyncBackoffMax, "sync-backoff-max", 5*time.Minute,
+		"The maximum delay between retries of a failing ExternalSecret.")
+	fs.DurationVar(&s.StoreCheckInterval, "store-check-interval", 5*time.Minute,
+		"The interval after which SecretStores and ClusterSecretStores are validated again. "+
+			"A value of 0 disables periodic validation.")
+	fs.BoolVar(&s.EnableWebhooks, "enable-webhooks", false,
+		"If true, the admission webhooks for ExternalSecrets, SecretStores and ClusterSecretStores "+
+			"are served. Requires a TLS certificate and key in the TLS certificate directory.")
+	fs.IntVar(&s.WebhookPort, "webhook-port", 9443,
+		"The port number that the admission webhook server should listen on.")
+	fs.StringVar(&s.TLSCertDir, "tls-cert-dir", "",
+		"The directory containing the tls.crt and tls.key of the admission webhook server. "+
+			"If not specified, /tmp/k8s-webhook-server/serving-certs is used.")
 	fs.IntVar(&s.HealthPort, "health-port", 8400,
 		"The port number to listen on for health connections.")
 	fs.IntVar(&s.MetricPort, "metric-port", 9321,
@@ -83,5 +110,17 @@ func (s *ControllerOptions) InitFlags(fs *pflag.FlagSet) {
 }
 
 func (s *ControllerOptions) Validate() error {
+	if s.DefaultRefreshInterval < 0 {
+		return fmt.Errorf("invalid default refresh interval %q: must not be negative", s.DefaultRefreshInterval)
+	}
+	if s.StoreCheckInterval < 0 {
+		return fmt.Errorf("invalid store check interval %q: must not be negative", s.StoreCheckInterval)
+	}
+	if s.SyncBackoffBase <= 0 {
+		return fmt.Errorf("invalid sync backoff base %q: must be positive", s.SyncBackoffBase)
+	}
+	if s.SyncBackoffMax < s.SyncBackoffBase {
+		return fmt.Errorf("invalid sync backoff max %q: must not be less than the sync backoff base %q", s.SyncBackoffMax, s.SyncBackoffBase)
+	}
 	return nil
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
 	"github.com/aws/aws-sdk-go-v2/aws/external"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 )
 
 // CreateAWSSecretsManagerSecret creates a sm secret with the given value
@@ -42,6 +43,25 @@ func CreateAWSSecretsManagerSecret(namespace, name, secret string) error {
 	return err
 }
 
+// CreateAWSParameter creates a SecureString parameter with the given value
+func CreateAWSParameter(namespace, name, value string) error {
+	cfg, err := external.LoadDefaultAWSConfig()
+	if err != nil {
+		return err
+	}
+	cfg.Region = "us-east-1"
+	cfg.Credentials = aws.NewStaticCredentialsProvider("foobar", "foobar", "secret-manager")
+	cfg.EndpointResolver = &localResolver{namespace: namespace}
+	client := ssm.New(cfg)
+	req := client.PutParameterRequest(&ssm.PutParameterInput{
+		Name:  aws.String(name),
+		Type:  ssm.ParameterTypeSecureString,
+		Value: aws.String(value),
+	})
+	_, err = req.Send(context.Background())
+	return err
+}
+
 // localResolver resolves endpoints to
 type localResolver struct {
 	endpoints.Resolver
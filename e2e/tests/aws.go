@@ -125,4 +125,77 @@ var _ = ginkgo.Describe("[aws]", func() {
 			"password-from-aws": []byte("abc123xyz456"),
 		}), "The generated secret should be created")
 	})
+
+	ginkgo.It("should sync parameters", func() {
+		// create SSM parameters
+		for name, value := range map[string]string{
+			"/my-app/username":    "bob",
+			"/my-app/db/password": "abc123xyz456",
+		} {
+			err := framework.CreateAWSParameter(f.Namespace, name, value)
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		}
+
+		key := types.NamespacedName{
+			Name:      "aws-ssm-secret",
+			Namespace: f.Namespace,
+		}
+
+		// create store
+		store := &smv1alpha1.SecretStore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "localstack-ssm",
+				Namespace: f.Namespace,
+			},
+			Spec: smv1alpha1.SecretStoreSpec{
+				AWS: &smv1alpha1.AWSStore{
+					Service: smv1alpha1.AWSServiceParameterStore,
+					Region:  smmeta.String("us-east-1"),
+				},
+			},
+		}
+		ginkgo.By("Creating the SecretStore successfully")
+		gomega.Expect(f.KubeClient.Create(context.Background(), store)).Should(gomega.Succeed())
+
+		// create ES
+		ginkgo.By("Creating the ExternalSecret successfully")
+		gomega.Expect(f.KubeClient.Create(context.Background(), &smv1alpha1.ExternalSecret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.Name,
+				Namespace: f.Namespace,
+			},
+			Spec: smv1alpha1.ExternalSecretSpec{
+				StoreRef: smv1alpha1.ObjectReference{
+					Name: store.Name,
+					Kind: smv1alpha1.SecretStoreKind,
+				},
+				Data: []smv1alpha1.KeyReference{
+					{
+						SecretKey: "username-from-ssm",
+						RemoteRef: smv1alpha1.RemoteReference{
+							Name: "/my-app/username",
+						},
+					},
+				},
+				DataFrom: []smv1alpha1.RemoteReference{
+					{
+						Name: "/my-app",
+					},
+				},
+			},
+		})).Should(gomega.Succeed())
+
+		fetchedSecret := &corev1.Secret{}
+		gomega.Eventually(func() map[string][]byte {
+			ginkgo.By("Fetching the Secret successfully")
+			if err := f.KubeClient.Get(context.Background(), key, fetchedSecret); err != nil {
+				return nil
+			}
+			return fetchedSecret.Data
+		}, framework.DefaultTimeout, framework.Poll).Should(gomega.Equal(map[string][]byte{
+			"username-from-ssm": []byte("bob"),
+			"username":          []byte("bob"),
+			"db_password":       []byte("abc123xyz456"),
+		}), "The generated secret should be created")
+	})
 })
@@ -10,10 +10,13 @@ require (
 	github.com/imdario/mergo v0.3.11
 	github.com/onsi/ginkgo v1.14.2
 	github.com/onsi/gomega v1.10.3
+	github.com/prometheus/client_golang v1.0.0
+	github.com/prometheus/client_model v0.2.0
 	github.com/sirupsen/logrus v1.7.0
 	github.com/spf13/cobra v1.1.1
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 	google.golang.org/api v0.33.0
 	k8s.io/api v0.19.2
 	k8s.io/apimachinery v0.19.2
@@ -22,4 +25,5 @@ require (
 	k8s.io/utils v0.0.0-20201015054608-420da100c033
 	oss.indeed.com/go/go-groups v1.1.3
 	sigs.k8s.io/controller-runtime v0.6.3
+	sigs.k8s.io/yaml v1.2.0
 )
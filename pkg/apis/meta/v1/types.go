@@ -63,8 +63,8 @@ type ObjectReference struct {
 type SecretKeySelector struct {
 	// The name of the Secret resource being referred to.
 	LocalObjectReference `json:",inline"`
-	// Namespace of the resource being referred to. Ignored if referent is not cluster-scoped. cluster-scoped defaults
-	// to the namespace of the referent.
+	// Namespace of the resource being referred to. SecretStores may only refer to resources in their own namespace.
+	// ClusterSecretStores default to the namespace of the ExternalSecret.
 	// +optional
 	Namespace *string `json:"namespace,omitempty"`
 	// The key of the entry in the Secret resource's `data` field to be used. Some instances of this field may be
@@ -72,3 +72,16 @@ type SecretKeySelector struct {
 	// +optional
 	Key string `json:"key,omitempty"`
 }
+
+// A reference to a ServiceAccount resource.
+type ServiceAccountSelector struct {
+	// The name of the ServiceAccount resource being referred to.
+	Name string `json:"name"`
+	// Namespace of the resource being referred to. SecretStores may only refer to resources in their own namespace.
+	// ClusterSecretStores default to the namespace of the ExternalSecret.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+	// Audiences of the requested ServiceAccount token. Some instances of this field may be defaulted.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+}
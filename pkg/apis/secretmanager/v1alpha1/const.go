@@ -22,5 +22,47 @@ const (
 
 	DefaultVaultAppRoleAuthMountPath    = "approle"
 	DefaultVaultKubernetesAuthMountPath = "kubernetes"
+	DefaultVaultKubernetesAuthSecretKey = "token"
+	DefaultVaultJWTAuthMountPath        = "jwt"
+	DefaultVaultCertAuthMountPath       = "cert"
+	DefaultVaultUserPassAuthMountPath   = "userpass"
+	DefaultVaultLDAPAuthMountPath       = "ldap"
+	DefaultVaultIAMAuthMountPath        = "aws"
 	DefaultVaultKVEngineVersion         = VaultKVStoreV2
+	DefaultVaultEngine                  = VaultEngineKV
+
+	DefaultGCPSecretVersion = "latest"
+
+	DefaultAWSService = AWSServiceSecretsManager
+
+	// RetainedSecretAnnotation is set on secrets retained after the deletion of
+	// the owning ExternalSecret to the name of the ExternalSecret. The secret is
+	// adopted by a new ExternalSecret of the same name.
+	RetainedSecretAnnotation = "secret-manager.itscontained.io/retained-by"
+
+	// ManagedSecretAnnotation is set on secrets created with the creation
+	// policy None to the name of the ExternalSecret, as they have no owner
+	// reference to tell them apart from secrets not managed by secret-manager.
+	ManagedSecretAnnotation = "secret-manager.itscontained.io/managed-by"
 )
+
+// KubernetesAPIAudiences are the usual audiences of the Kubernetes API server.
+// ServiceAccount tokens sent to a Vault server may not be issued for them, as
+// whoever controls the server could use the tokens against the API server.
+var KubernetesAPIAudiences = []string{
+	"https://kubernetes.default.svc",
+	"https://kubernetes.default.svc.cluster.local",
+	"kubernetes.default.svc",
+	"kubernetes.default.svc.cluster.local",
+}
+
+// IsKubernetesAPIAudience returns whether the audience is one of the
+// KubernetesAPIAudiences.
+func IsKubernetesAPIAudience(audience string) bool {
+	for _, apiAudience := range KubernetesAPIAudiences {
+		if audience == apiAudience {
+			return true
+		}
+	}
+	return false
+}
@@ -17,6 +17,8 @@ package v1alpha1
 import (
 	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
 
+	corev1 "k8s.io/api/core/v1"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,6 +41,21 @@ type ExternalSecretSpec struct {
 	// +optional
 	Template []byte `json:"template,omitempty"`
 
+	// TemplateEngine defines how the template is applied, one of "None" or
+	// "Go". With "None" the template is merged into the generated secret as-is.
+	// With "Go" the values of the data, stringData, labels and annotations of
+	// the template are rendered as Go templates with the fetched secret data as
+	// input before the template is merged. Defaults to "None".
+	// +kubebuilder:validation:Enum=None;Go
+	// +optional
+	TemplateEngine TemplateEngine `json:"templateEngine,omitempty"`
+
+	// TemplateFrom references ConfigMaps or Secrets in the namespace of the
+	// ExternalSecret whose keys are added to the `stringData` of the template.
+	// Keys set in the inline template take precedence.
+	// +optional
+	TemplateFrom []TemplateFrom `json:"templateFrom,omitempty"`
+
 	// Data is a list of references to secret values.
 	// +optional
 	Data []KeyReference `json:"data,omitempty"`
@@ -46,6 +63,148 @@ type ExternalSecretSpec struct {
 	// DataFrom references a map of secrets to embed within the generated secret.
 	// +optional
 	DataFrom []RemoteReference `json:"dataFrom,omitempty"`
+
+	// RefreshInterval is the amount of time after which the generated secret is
+	// re-synced with the SecretStore, e.g: "1h". A value of "0s" disables periodic
+	// refresh. If not set the controller-wide default refresh interval is used.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// Target describes the generated secret, how it is created and what
+	// happens to it when the ExternalSecret is deleted.
+	// +optional
+	Target ExternalSecretTarget `json:"target,omitempty"`
+}
+
+// TemplateEngine defines how the template of an ExternalSecret is applied.
+type TemplateEngine string
+
+const (
+	// TemplateEngineNone merges the template into the generated secret as-is.
+	TemplateEngineNone TemplateEngine = "None"
+
+	// TemplateEngineGo renders the template values with text/template.
+	TemplateEngineGo TemplateEngine = "Go"
+)
+
+// TemplateFrom references a ConfigMap or a Secret holding template files.
+// Exactly one of ConfigMap or Secret must be set.
+type TemplateFrom struct {
+	// ConfigMap holding template files.
+	// +optional
+	ConfigMap *TemplateRef `json:"configMap,omitempty"`
+
+	// Secret holding template files.
+	// +optional
+	Secret *TemplateRef `json:"secret,omitempty"`
+}
+
+// TemplateRef references the template files of a ConfigMap or Secret.
+type TemplateRef struct {
+	// Name of the ConfigMap or Secret.
+	Name string `json:"name"`
+
+	// Keys of the template files to use. If not set all keys are used.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+}
+
+// ExternalSecretCreationPolicy defines how the generated secret is created.
+type ExternalSecretCreationPolicy string
+
+const (
+	// CreatePolicyOwner creates the secret and sets the ExternalSecret as its
+	// controller owner. An existing secret which is not managed by the
+	// ExternalSecret is not overwritten.
+	CreatePolicyOwner ExternalSecretCreationPolicy = "Owner"
+
+	// CreatePolicyMerge merges the fetched data into an existing secret,
+	// keeping its other keys and owners. The secret is not created.
+	CreatePolicyMerge ExternalSecretCreationPolicy = "Merge"
+
+	// CreatePolicyNone creates or updates the secret without setting an owner
+	// reference, so it is not garbage collected with the ExternalSecret. An
+	// existing secret which is not managed by the ExternalSecret is not
+	// overwritten.
+	CreatePolicyNone ExternalSecretCreationPolicy = "None"
+)
+
+// ExternalSecretDeletionPolicy defines what happens to the generated secret
+// when the ExternalSecret is deleted.
+type ExternalSecretDeletionPolicy string
+
+const (
+	// DeletionPolicyDelete deletes the secret together with the ExternalSecret.
+	// It cannot be used with CreatePolicyMerge.
+	DeletionPolicyDelete ExternalSecretDeletionPolicy = "Delete"
+
+	// DeletionPolicyRetain keeps the secret, which is adopted again by an
+	// ExternalSecret of the same name, e.g. when the ExternalSecret is re-created.
+	DeletionPolicyRetain ExternalSecretDeletionPolicy = "Retain"
+
+	// DeletionPolicyOrphan keeps the secret and removes all references to the
+	// ExternalSecret, so it is treated as a secret not managed by secret-manager.
+	DeletionPolicyOrphan ExternalSecretDeletionPolicy = "Orphan"
+)
+
+// ExternalSecretTarget defines how the generated secret is managed.
+type ExternalSecretTarget struct {
+	// Name of the generated secret. Defaults to the name of the ExternalSecret.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// CreationPolicy defines how the secret is created, one of "Owner",
+	// "Merge" or "None". Defaults to "Owner".
+	// +kubebuilder:validation:Enum=Owner;Merge;None
+	// +optional
+	CreationPolicy ExternalSecretCreationPolicy `json:"creationPolicy,omitempty"`
+
+	// DeletionPolicy defines what happens to the secret when the ExternalSecret
+	// is deleted, one of "Delete", "Retain" or "Orphan". Defaults to "Delete"
+	// if the creation policy is "Owner" and to "Retain" otherwise.
+	// +kubebuilder:validation:Enum=Delete;Retain;Orphan
+	// +optional
+	DeletionPolicy ExternalSecretDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Type of the generated secret, e.g: "kubernetes.io/dockerconfigjson".
+	// Defaults to "Opaque" for created secrets.
+	// +optional
+	Type corev1.SecretType `json:"type,omitempty"`
+
+	// Immutable marks the generated secret as immutable. Changes of the
+	// secret data in the store are not propagated to an immutable secret.
+	// +optional
+	Immutable *bool `json:"immutable,omitempty"`
+
+	// Labels of the generated secret. If not set the labels of the
+	// ExternalSecret are used.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations of the generated secret. If not set the annotations of the
+	// ExternalSecret are used.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GetCreationPolicy returns the creation policy, defaulting to Owner.
+func (t *ExternalSecretTarget) GetCreationPolicy() ExternalSecretCreationPolicy {
+	if t.CreationPolicy == "" {
+		return CreatePolicyOwner
+	}
+	return t.CreationPolicy
+}
+
+// GetDeletionPolicy returns the deletion policy, defaulting to Delete for
+// secrets owned by the ExternalSecret and to Retain otherwise.
+func (t *ExternalSecretTarget) GetDeletionPolicy() ExternalSecretDeletionPolicy {
+	if t.DeletionPolicy != "" {
+		return t.DeletionPolicy
+	}
+	if t.GetCreationPolicy() == CreatePolicyOwner {
+		return DeletionPolicyDelete
+	}
+	return DeletionPolicyRetain
 }
 
 // ObjectReference is a reference to an object with a given name, kind and group.
@@ -75,7 +234,8 @@ type RemoteReference struct {
 
 	// Property to extract secret value at path in the SecretStore.
 	// Can be omitted if not supported by SecretStore or if entire secret should
-	// be fetched as in dataFrom reference.
+	// be fetched as in dataFrom reference. Nested values of JSON secrets can be
+	// addressed with dotted paths, e.g. "db.primary.password" or "hosts.0".
 	// +optional
 	Property *string `json:"property,omitempty"`
 
@@ -83,13 +243,63 @@ type RemoteReference struct {
 	// by the referenced SecretStore.
 	// +optional
 	Version *string `json:"version,omitempty"`
+
+	// Parameters are sent with the request for the secret, if supported by
+	// the referenced SecretStore, e.g. the common name of a certificate
+	// issued by a Vault PKI secrets engine.
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// DecodingStrategy is applied to the fetched value, or to every value of
+	// the map fetched in a dataFrom reference. One of "None", "Base64",
+	// "Base64URL", "Hex" or "Auto". "Auto" decodes values which are valid
+	// base64 or base64url and keeps all other values as-is. Defaults to "None".
+	// +kubebuilder:validation:Enum=None;Base64;Base64URL;Hex;Auto
+	// +optional
+	DecodingStrategy DecodingStrategy `json:"decodingStrategy,omitempty"`
 }
 
+// DecodingStrategy defines how a value fetched from the store is decoded.
+type DecodingStrategy string
+
+const (
+	// DecodingStrategyNone keeps the value as-is.
+	DecodingStrategyNone DecodingStrategy = "None"
+
+	// DecodingStrategyBase64 decodes standard base64, with or without padding.
+	DecodingStrategyBase64 DecodingStrategy = "Base64"
+
+	// DecodingStrategyBase64URL decodes URL-safe base64, with or without padding.
+	DecodingStrategyBase64URL DecodingStrategy = "Base64URL"
+
+	// DecodingStrategyHex decodes hexadecimal.
+	DecodingStrategyHex DecodingStrategy = "Hex"
+
+	// DecodingStrategyAuto decodes base64 or base64url if the value is valid
+	// in either encoding and keeps it as-is otherwise.
+	DecodingStrategyAuto DecodingStrategy = "Auto"
+)
+
 // ExternalSecretStatus defines the observed state of ExternalSecret
 type ExternalSecretStatus struct {
 	// List of status conditions to indicate the status of ExternalSecret.
 	// Known condition types are `Ready`.
 	smmeta.ConditionedStatus `json:",inline"`
+
+	// RefreshTime is the time the generated secret was last successfully synced
+	// with the SecretStore.
+	// +optional
+	RefreshTime metav1.Time `json:"refreshTime,omitempty"`
+
+	// FailedSyncs is the number of consecutive failed attempts to sync the
+	// generated secret with the SecretStore. It is reset after a successful sync.
+	// +optional
+	FailedSyncs int32 `json:"failedSyncs,omitempty"`
+
+	// TargetName is the name of the last successfully synced secret. A secret
+	// of a previous target name is released according to the deletion policy.
+	// +optional
+	TargetName string `json:"targetName,omitempty"`
 }
 
 // +kubebuilder:object:root=true
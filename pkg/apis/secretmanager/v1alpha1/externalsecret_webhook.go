@@ -0,0 +1,170 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (e *ExternalSecret) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(e).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-secret-manager-itscontained-io-v1alpha1-externalsecret,mutating=true,failurePolicy=fail,groups=secret-manager.itscontained.io,resources=externalsecrets,versions=v1alpha1,name=mexternalsecret.secret-manager.itscontained.io
+
+var _ webhook.Defaulter = &ExternalSecret{}
+
+// Default implements webhook.Defaulter.
+func (e *ExternalSecret) Default() {
+	SetExternalSecretSpecDefaults(&e.Spec)
+}
+
+// SetExternalSecretSpecDefaults defaults the kind of the store reference, the
+// template engine and the target policies. Backend specific defaults of the
+// remote references, e.g. the GCP secret version, are applied by the store
+// when reading, as the referenced store may change after admission.
+func SetExternalSecretSpecDefaults(spec *ExternalSecretSpec) {
+	if spec.StoreRef.Kind == "" {
+		spec.StoreRef.Kind = SecretStoreKind
+	}
+	if (spec.Template != nil || len(spec.TemplateFrom) > 0) && spec.TemplateEngine == "" {
+		spec.TemplateEngine = TemplateEngineNone
+	}
+	spec.Target.CreationPolicy = spec.Target.GetCreationPolicy()
+	spec.Target.DeletionPolicy = spec.Target.GetDeletionPolicy()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-secret-manager-itscontained-io-v1alpha1-externalsecret,mutating=false,failurePolicy=fail,groups=secret-manager.itscontained.io,resources=externalsecrets,versions=v1alpha1,name=vexternalsecret.secret-manager.itscontained.io
+
+var _ webhook.Validator = &ExternalSecret{}
+
+// ValidateCreate implements webhook.Validator.
+func (e *ExternalSecret) ValidateCreate() error {
+	return e.validate()
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (e *ExternalSecret) ValidateUpdate(old runtime.Object) error {
+	return e.validate()
+}
+
+// ValidateDelete implements webhook.Validator.
+func (e *ExternalSecret) ValidateDelete() error {
+	return nil
+}
+
+func (e *ExternalSecret) validate() error {
+	errs := ValidateExternalSecretSpec(&e.Spec, field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(ExtSecretGroupVersionKind.GroupKind(), e.Name, errs)
+}
+
+// ValidateExternalSecretSpec validates the store reference, the data keys and
+// the templates of an ExternalSecret.
+func ValidateExternalSecretSpec(spec *ExternalSecretSpec, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	storeRefPath := fldPath.Child("storeRef")
+	if spec.StoreRef.Name == "" {
+		errs = append(errs, field.Required(storeRefPath.Child("name"), ""))
+	}
+	switch spec.StoreRef.Kind {
+	case "", SecretStoreKind, ClusterSecretStoreKind:
+	default:
+		errs = append(errs, field.NotSupported(storeRefPath.Child("kind"), spec.StoreRef.Kind,
+			[]string{SecretStoreKind, ClusterSecretStoreKind}))
+	}
+
+	secretKeys := make(map[string]bool, len(spec.Data))
+	for i, ref := range spec.Data {
+		dataPath := fldPath.Child("data").Index(i)
+		if ref.SecretKey == "" {
+			errs = append(errs, field.Required(dataPath.Child("secretKey"), ""))
+		} else if secretKeys[ref.SecretKey] {
+			errs = append(errs, field.Duplicate(dataPath.Child("secretKey"), ref.SecretKey))
+		}
+		secretKeys[ref.SecretKey] = true
+		if ref.RemoteRef.Name == "" {
+			errs = append(errs, field.Required(dataPath.Child("remoteRef", "name"), ""))
+		}
+	}
+	for i, ref := range spec.DataFrom {
+		if ref.Name == "" {
+			errs = append(errs, field.Required(fldPath.Child("dataFrom").Index(i).Child("name"), ""))
+		}
+	}
+
+	if spec.Template != nil {
+		if err := json.Unmarshal(spec.Template, &corev1.Secret{}); err != nil {
+			errs = append(errs, field.Invalid(fldPath.Child("template"), string(spec.Template),
+				"cannot be unmarshalled into a Secret: "+err.Error()))
+		}
+	}
+
+	for i, from := range spec.TemplateFrom {
+		fromPath := fldPath.Child("templateFrom").Index(i)
+		switch {
+		case from.ConfigMap == nil && from.Secret == nil:
+			errs = append(errs, field.Required(fromPath, "must specify one of configMap or secret"))
+		case from.ConfigMap != nil && from.Secret != nil:
+			errs = append(errs, field.Invalid(fromPath.Child("secret"), from.Secret.Name,
+				"cannot be specified together with configMap"))
+		}
+		if from.ConfigMap != nil && from.ConfigMap.Name == "" {
+			errs = append(errs, field.Required(fromPath.Child("configMap", "name"), ""))
+		}
+		if from.Secret != nil && from.Secret.Name == "" {
+			errs = append(errs, field.Required(fromPath.Child("secret", "name"), ""))
+		}
+	}
+
+	targetPath := fldPath.Child("target")
+	if spec.Target.Name != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(spec.Target.Name) {
+			errs = append(errs, field.Invalid(targetPath.Child("name"), spec.Target.Name, msg))
+		}
+	}
+	errs = append(errs, metav1validation.ValidateLabels(spec.Target.Labels, targetPath.Child("labels"))...)
+	errs = append(errs, apivalidation.ValidateAnnotations(spec.Target.Annotations, targetPath.Child("annotations"))...)
+	// a secret merged into is not created by the ExternalSecret and must
+	// not be deleted with it
+	if spec.Target.GetCreationPolicy() == CreatePolicyMerge && spec.Target.GetDeletionPolicy() == DeletionPolicyDelete {
+		errs = append(errs, field.Invalid(targetPath.Child("deletionPolicy"), spec.Target.DeletionPolicy,
+			fmt.Sprintf("cannot be used with creationPolicy %s", CreatePolicyMerge)))
+	}
+
+	if spec.RefreshInterval != nil && spec.RefreshInterval.Duration < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("refreshInterval"), spec.RefreshInterval.Duration.String(),
+			"must not be negative"))
+	}
+	return errs
+}
@@ -32,6 +32,7 @@ type GenericStore interface {
 	GetTypeMeta() *metav1.TypeMeta
 	GetObjectMeta() *metav1.ObjectMeta
 	GetSpec() *SecretStoreSpec
+	GetStatus() *SecretStoreStatus
 }
 
 // +kubebuilder:object:root:false
@@ -58,6 +59,9 @@ func (c *ClusterSecretStore) GetSpec() *SecretStoreSpec {
 func (c *ClusterSecretStore) SetSpec(spec SecretStoreSpec) {
 	c.Spec = spec
 }
+func (c *ClusterSecretStore) GetStatus() *SecretStoreStatus {
+	return &c.Status
+}
 func (c *ClusterSecretStore) Copy() GenericStore {
 	return c.DeepCopy()
 }
@@ -77,6 +81,9 @@ func (c *SecretStore) GetSpec() *SecretStoreSpec {
 func (c *SecretStore) SetSpec(spec SecretStoreSpec) {
 	c.Spec = spec
 }
+func (c *SecretStore) GetStatus() *SecretStoreStatus {
+	return &c.Status
+}
 func (c *SecretStore) Copy() GenericStore {
 	return c.DeepCopy()
 }
@@ -14,21 +14,103 @@ limitations under the License.
 
 package v1alpha1
 
-import smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+import (
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // Configures an store to sync secrets using AWS SecretManager
 type AWSStore struct {
+	// Service is the AWS service secrets are read from, one of
+	// "SecretsManager" or "ParameterStore". Defaults to "SecretsManager".
+	// +kubebuilder:validation:Enum=SecretsManager;ParameterStore
+	// +optional
+	Service AWSServiceType `json:"service,omitempty"`
 	// Region configures the region to send requests to.
 	// +optional
 	Region *string `json:"region,omitempty"`
 	// Auth configures how secret-manager authenticates with AWS.
 	// +optional
 	AuthSecretRef *AWSAuth `json:"authSecretRef,omitempty"`
+	// Role is the ARN of a role assumed with the configured credentials.
+	// +optional
+	Role string `json:"role,omitempty"`
+	// ExternalID is passed when assuming Role, as required by the trust policy of some roles in other accounts.
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
+	// SessionName is the name of the role session of Role, defaults to "secret-manager". It consists of 2 to 64
+	// letters, digits and the characters +=,.@_-.
+	// +optional
+	SessionName string `json:"sessionName,omitempty"`
+	// SessionTags are attached to the role session of Role.
+	// +optional
+	SessionTags []AWSSessionTag `json:"sessionTags,omitempty"`
+	// Duration of the role session of Role, defaults to one hour. At most one hour if the configured credentials
+	// are assumed from another role, e.g. with JWT auth, and twelve hours otherwise.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// RoleChain is an ordered list of roles assumed after Role, each with the credentials of the previous role,
+	// e.g. to reach roles in other accounts through a central account.
+	// +optional
+	RoleChain []AWSAssumeRole `json:"roleChain,omitempty"`
+}
+
+// AWSAssumeRole configures a role to assume.
+type AWSAssumeRole struct {
+	// Role is the ARN of the role to assume.
+	Role string `json:"role"`
+	// ExternalID is passed when assuming the role, as required by the trust policy of some roles in other accounts.
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
+	// SessionName is the name of the role session, defaults to "secret-manager". It consists of 2 to 64 letters,
+	// digits and the characters +=,.@_-.
+	// +optional
+	SessionName string `json:"sessionName,omitempty"`
+	// SessionTags are attached to the role session.
+	// +optional
+	SessionTags []AWSSessionTag `json:"sessionTags,omitempty"`
+	// Duration of the role session, defaults to and is at most one hour.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// AWSSessionTag is a tag attached to a role session.
+type AWSSessionTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
+// AssumeRoles returns the roles to assume in order, starting with Role.
+func (s *AWSStore) AssumeRoles() []AWSAssumeRole {
+	var roles []AWSAssumeRole
+	if s.Role != "" {
+		roles = append(roles, AWSAssumeRole{
+			Role:        s.Role,
+			ExternalID:  s.ExternalID,
+			SessionName: s.SessionName,
+			SessionTags: s.SessionTags,
+			Duration:    s.Duration,
+		})
+	}
+	return append(roles, s.RoleChain...)
+}
+
+// AWSServiceType is an AWS service secrets can be read from.
+type AWSServiceType string
+
+const (
+	// AWSServiceSecretsManager reads secrets from AWS Secrets Manager.
+	AWSServiceSecretsManager AWSServiceType = "SecretsManager"
+
+	// AWSServiceParameterStore reads parameters from the AWS Systems Manager
+	// Parameter Store.
+	AWSServiceParameterStore AWSServiceType = "ParameterStore"
+)
+
 // Configuration used to authenticate with AWS.
-// Any of `AccessKeyID`, `SecretAccessKey` or `Role` can be specified. If not set we fall-back to using env vars, shared
-// credentials file or AWS Instance metadata
+// Any of `AccessKeyID`, `SecretAccessKey` or `Role` can be specified, or alternatively `JWT`. If not set we fall-back
+// to using env vars, shared credentials file or AWS Instance metadata
 type AWSAuth struct {
 	// The AccessKeyID is used for authentication. If not set we fall-back to using env vars, shared credentials file
 	// or AWS Instance metadata
@@ -43,6 +125,30 @@ type AWSAuth struct {
 	// Role is a Role ARN which the SecretManager provider will assume using either the explicit credentials
 	// AccessKeyID/SecretAccessKey or the inferred credentials from environment variables, shared credentials
 	// file or AWS Instance metadata
+	// Deprecated: use the role of the store instead, which supports further options.
 	// +optional
 	Role *smmeta.SecretKeySelector `json:"role,omitempty"`
+	// JWT authenticates with a token of a Kubernetes ServiceAccount, e.g. when using IAM Roles for Service Accounts.
+	// Cannot be combined with AccessKeyID/SecretAccessKey.
+	// +optional
+	JWT *AWSJWTAuth `json:"jwt,omitempty"`
+}
+
+// SecretKeySelectors returns the references to Secrets of the auth section,
+// which may be nil.
+func (a *AWSAuth) SecretKeySelectors() []smmeta.SecretKeySelector {
+	if a == nil {
+		return nil
+	}
+	return secretKeySelectorValues([]*smmeta.SecretKeySelector{a.AccessKeyID, a.SecretAccessKey, a.Role})
+}
+
+// AWSJWTAuth authenticates with AWS by assuming a role with the web identity
+// token of a Kubernetes ServiceAccount.
+type AWSJWTAuth struct {
+	// ServiceAccountRef is the ServiceAccount a token is requested for using the TokenRequest API. The audiences
+	// default to "sts.amazonaws.com".
+	ServiceAccountRef smmeta.ServiceAccountSelector `json:"serviceAccountRef"`
+	// Role is the ARN of the role assumed with the ServiceAccount token.
+	Role string `json:"role"`
 }
@@ -34,16 +34,18 @@ type SecretStoreSpec struct {
 	GCP *GCPStore `json:"gcp,omitempty"`
 }
 
+// SecretStoreStatus defines the observed state of the SecretStore
 type SecretStoreStatus struct {
 	// List of status conditions to indicate the status of SecretStore.
 	// Known condition types are `Ready`.
 	// +optional
-	Conditions smmeta.ConditionedStatus `json:",inline"`
+	smmeta.ConditionedStatus `json:",inline"`
 }
 
 // +kubebuilder:object:root=true
 
 // SecretStore represents a secure external location for storing secrets, which can be referenced as part of `storeRef` fields
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Namespaced,categories={secretmanager},shortName=ss
@@ -51,7 +53,8 @@ type SecretStore struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec SecretStoreSpec `json:"spec,omitempty"`
+	Spec   SecretStoreSpec   `json:"spec,omitempty"`
+	Status SecretStoreStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -60,12 +63,13 @@ type SecretStore struct {
 type SecretStoreList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata,omitempty"`
-	Items           []ExternalSecret `json:"items"`
+	Items           []SecretStore `json:"items"`
 }
 
 // +kubebuilder:object:root=true
 
 // ClusterSecretStore represents a secure external location for storing secrets, which can be referenced as part of `storeRef` fields
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={secretmanager},shortName=css
@@ -73,7 +77,8 @@ type ClusterSecretStore struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec SecretStoreSpec `json:"spec,omitempty"`
+	Spec   SecretStoreSpec   `json:"spec,omitempty"`
+	Status SecretStoreStatus `json:"status,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -82,5 +87,16 @@ type ClusterSecretStore struct {
 type ClusterSecretStoreList struct {
 	metav1.TypeMeta `json:",inline"`
 	metav1.ListMeta `json:"metadata,omitempty"`
-	Items           []ExternalSecret `json:"items"`
+	Items           []ClusterSecretStore `json:"items"`
+}
+
+// secretKeySelectorValues returns the values of the selectors which are set.
+func secretKeySelectorValues(selectors []*smmeta.SecretKeySelector) []smmeta.SecretKeySelector {
+	var out []smmeta.SecretKeySelector
+	for _, selector := range selectors {
+		if selector != nil {
+			out = append(out, *selector)
+		}
+	}
+	return out
 }
@@ -25,6 +25,18 @@ const (
 	VaultKVStoreV2 VaultKVStoreVersion = "v2"
 )
 
+// VaultEngineType is the type of a Vault secrets engine.
+type VaultEngineType string
+
+const (
+	// VaultEngineKV reads secrets from a KV secrets engine.
+	VaultEngineKV VaultEngineType = "KV"
+
+	// VaultEngineDynamic reads or writes arbitrary paths of a secrets engine,
+	// e.g. to issue database credentials or certificates.
+	VaultEngineDynamic VaultEngineType = "Dynamic"
+)
+
 // Configures an store to sync secrets using a HashiCorp Vault
 // KV backend.
 type VaultStore struct {
@@ -40,8 +52,18 @@ type VaultStore struct {
 	// if not present in specified path.
 	Path string `json:"path"`
 
+	// Engine is the type of the secrets engine mounted at Path, one of "KV" or
+	// "Dynamic". Defaults to "KV". With "Dynamic" the name of a remote
+	// reference is a path below Path, e.g. "creds/my-role", which is read with
+	// GET, or written with POST if the reference sets parameters. The data of
+	// the response is used as secret and is renewed or issued again before
+	// its lease expires.
+	// +kubebuilder:validation:Enum=KV;Dynamic
+	// +optional
+	Engine VaultEngineType `json:"engine,omitempty"`
+
 	// Version is the Vault KV secret engine version. This can be either "v1" or
-	// "v2". Version defaults to "v2".
+	// "v2". Version defaults to "v2". Only used by the "KV" engine.
 	// +optional
 	Version *VaultKVStoreVersion `json:"version,omitempty"`
 
@@ -59,7 +81,8 @@ type VaultStore struct {
 }
 
 // Configuration used to authenticate with a Vault server.
-// Only one of `tokenSecretRef`, `appRole` or `kubernetes` may be specified.
+// Only one of `tokenSecretRef`, `appRole`, `kubernetes`, `jwt`, `cert`,
+// `userPass`, `ldap` or `iam` may be specified.
 type VaultAuth struct {
 	// TokenSecretRef authenticates with Vault by presenting a token.
 	// +optional
@@ -70,10 +93,71 @@ type VaultAuth struct {
 	// +optional
 	AppRole *VaultAppRole `json:"appRole,omitempty"`
 
-	// Kubernetes authenticates with Vault by passing the ServiceAccount
-	// token stored in the named Secret resource to the Vault server.
+	// Kubernetes authenticates with Vault by passing a ServiceAccount token,
+	// stored in a Secret resource or requested for a ServiceAccount, to the
+	// Vault server.
 	// +optional
 	Kubernetes *VaultKubernetesAuth `json:"kubernetes,omitempty"`
+
+	// JWT authenticates with Vault using the JWT/OIDC auth mechanism, with a
+	// token of a Kubernetes ServiceAccount or a JWT stored in a Kubernetes
+	// Secret resource.
+	// +optional
+	JWT *VaultJWTAuth `json:"jwt,omitempty"`
+
+	// Cert authenticates with Vault using the TLS certificate auth mechanism,
+	// with the client certificate stored in a Kubernetes Secret resource.
+	// +optional
+	Cert *VaultCertAuth `json:"cert,omitempty"`
+
+	// UserPass authenticates with Vault using the username and password auth
+	// mechanism, with the password stored in a Kubernetes Secret resource.
+	// +optional
+	UserPass *VaultUserPassAuth `json:"userPass,omitempty"`
+
+	// LDAP authenticates with Vault using the LDAP auth mechanism, with the
+	// password stored in a Kubernetes Secret resource.
+	// +optional
+	LDAP *VaultLDAPAuth `json:"ldap,omitempty"`
+
+	// IAM authenticates with Vault using the AWS IAM auth mechanism, by
+	// signing a sts:GetCallerIdentity request with AWS credentials.
+	// +optional
+	IAM *VaultIAMAuth `json:"iam,omitempty"`
+}
+
+// SecretKeySelectors returns the references to Secrets of the configured
+// authentication methods. Fields referencing Secrets added to the auth types
+// must be added here as well, so that changes of the Secrets are picked up.
+func (a *VaultAuth) SecretKeySelectors() []smmeta.SecretKeySelector {
+	selectors := []*smmeta.SecretKeySelector{a.TokenSecretRef}
+	if a.AppRole != nil {
+		selectors = append(selectors, &a.AppRole.SecretRef)
+	}
+	if a.Kubernetes != nil && a.Kubernetes.SecretRef != nil {
+		secretRef := *a.Kubernetes.SecretRef
+		if secretRef.Key == "" {
+			secretRef.Key = DefaultVaultKubernetesAuthSecretKey
+		}
+		selectors = append(selectors, &secretRef)
+	}
+	if a.JWT != nil {
+		selectors = append(selectors, a.JWT.SecretRef)
+	}
+	if a.Cert != nil {
+		selectors = append(selectors, &a.Cert.ClientCert, &a.Cert.SecretRef)
+	}
+	if a.UserPass != nil {
+		selectors = append(selectors, &a.UserPass.SecretRef)
+	}
+	if a.LDAP != nil {
+		selectors = append(selectors, &a.LDAP.SecretRef)
+	}
+	out := secretKeySelectorValues(selectors)
+	if a.IAM != nil {
+		out = append(out, a.IAM.AuthSecretRef.SecretKeySelectors()...)
+	}
+	return out
 }
 
 // VaultAppRole authenticates with Vault using the App Role auth mechanism,
@@ -96,17 +180,25 @@ type VaultAppRole struct {
 }
 
 // Authenticate against Vault using a Kubernetes ServiceAccount token stored in
-// a Secret.
+// a Secret or requested for a ServiceAccount. At most one of
+// `serviceAccountRef` or `secretRef` may be specified.
 type VaultKubernetesAuth struct {
 	// Path where the Kubernetes authentication backend is mounted in Vault, e.g:
 	// "kubernetes"
 	// +kubebuilder:default=kubernetes
 	Path string `json:"mountPath"`
 
+	// Optional ServiceAccount a short-lived token is requested for using the
+	// TokenRequest API. The audiences default to "vault" and may not be those
+	// of the Kubernetes API server, as the token is sent to the server. The
+	// Vault role must set the same audience.
+	// +optional
+	ServiceAccountRef *smmeta.ServiceAccountSelector `json:"serviceAccountRef,omitempty"`
+
 	// Optional secret field containing a Kubernetes ServiceAccount JWT used
 	// for authenticating with Vault. If a name is specified without a key,
-	// `token` is the default. If one is not specified, the one bound to
-	// the controller will be used.
+	// `token` is the default. If neither this nor `serviceAccountRef` is
+	// specified, the one bound to the controller will be used.
 	// +optional
 	SecretRef *smmeta.SecretKeySelector `json:"secretRef,omitempty"`
 
@@ -114,3 +206,109 @@ type VaultKubernetesAuth struct {
 	// Kubernetes ServiceAccount with a set of Vault policies.
 	Role string `json:"role"`
 }
+
+// VaultJWTAuth authenticates with Vault using the JWT/OIDC auth mechanism.
+// Exactly one of `serviceAccountRef` or `secretRef` must be specified.
+type VaultJWTAuth struct {
+	// Path where the JWT authentication backend is mounted in Vault, e.g:
+	// "jwt"
+	// +kubebuilder:default=jwt
+	Path string `json:"path"`
+
+	// Role is the Vault role to authenticate as. If not set, the default role
+	// of the authentication backend is used.
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// ServiceAccountRef is the ServiceAccount a token is requested for using
+	// the TokenRequest API. The audiences default to "vault" and may not be
+	// those of the Kubernetes API server, as the token is sent to the server.
+	// +optional
+	ServiceAccountRef *smmeta.ServiceAccountSelector `json:"serviceAccountRef,omitempty"`
+
+	// Reference to a key in a Secret that contains the JWT used to
+	// authenticate with Vault.
+	// +optional
+	SecretRef *smmeta.SecretKeySelector `json:"secretRef,omitempty"`
+}
+
+// VaultCertAuth authenticates with Vault using the TLS certificate auth
+// mechanism, presenting the client certificate in the TLS handshake.
+type VaultCertAuth struct {
+	// Path where the TLS certificate authentication backend is mounted in
+	// Vault, e.g: "cert"
+	// +kubebuilder:default=cert
+	Path string `json:"path"`
+
+	// Name of the certificate role to authenticate against. If not set, all
+	// roles matching the client certificate are tried.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Reference to a key in a Secret that contains the PEM encoded client
+	// certificate.
+	ClientCert smmeta.SecretKeySelector `json:"clientCert"`
+
+	// Reference to a key in a Secret that contains the PEM encoded private key
+	// of the client certificate.
+	SecretRef smmeta.SecretKeySelector `json:"secretRef"`
+}
+
+// VaultUserPassAuth authenticates with Vault using the username and password
+// auth mechanism.
+type VaultUserPassAuth struct {
+	// Path where the username and password authentication backend is mounted
+	// in Vault, e.g: "userpass"
+	// +kubebuilder:default=userpass
+	Path string `json:"path"`
+
+	// Username to authenticate as.
+	Username string `json:"username"`
+
+	// Reference to a key in a Secret that contains the password of the user.
+	SecretRef smmeta.SecretKeySelector `json:"secretRef"`
+}
+
+// VaultLDAPAuth authenticates with Vault using the LDAP auth mechanism.
+type VaultLDAPAuth struct {
+	// Path where the LDAP authentication backend is mounted in Vault, e.g:
+	// "ldap"
+	// +kubebuilder:default=ldap
+	Path string `json:"path"`
+
+	// Username of the LDAP user to authenticate as.
+	Username string `json:"username"`
+
+	// Reference to a key in a Secret that contains the password of the LDAP
+	// user.
+	SecretRef smmeta.SecretKeySelector `json:"secretRef"`
+}
+
+// VaultIAMAuth authenticates with Vault using the AWS IAM auth mechanism.
+type VaultIAMAuth struct {
+	// Path where the AWS authentication backend is mounted in Vault, e.g:
+	// "aws"
+	// +kubebuilder:default=aws
+	Path string `json:"path"`
+
+	// Role is the Vault role to authenticate as.
+	Role string `json:"role"`
+
+	// Region of the STS endpoint the request is signed for. Defaults to
+	// "us-east-1", which uses the global STS endpoint.
+	// +optional
+	Region *string `json:"region,omitempty"`
+
+	// ServerID is sent in the X-Vault-AWS-IAM-Server-ID header, if required by
+	// the configuration of the authentication backend.
+	// +optional
+	ServerID *string `json:"serverID,omitempty"`
+
+	// AuthSecretRef configures the AWS credentials used to sign the request.
+	// If not set, the credentials are inferred from environment variables,
+	// shared credentials file or AWS Instance metadata. The role and role
+	// chain of an AWS store are not supported, use `role` or `jwt` of the
+	// credentials to sign the request as a role.
+	// +optional
+	AuthSecretRef *AWSAuth `json:"authSecretRef,omitempty"`
+}
@@ -0,0 +1,437 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (c *SecretStore) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(c).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-secret-manager-itscontained-io-v1alpha1-secretstore,mutating=true,failurePolicy=fail,groups=secret-manager.itscontained.io,resources=secretstores,versions=v1alpha1,name=msecretstore.secret-manager.itscontained.io
+
+var _ webhook.Defaulter = &SecretStore{}
+
+// Default implements webhook.Defaulter.
+func (c *SecretStore) Default() {
+	SetSecretStoreSpecDefaults(&c.Spec)
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-secret-manager-itscontained-io-v1alpha1-secretstore,mutating=false,failurePolicy=fail,groups=secret-manager.itscontained.io,resources=secretstores,versions=v1alpha1,name=vsecretstore.secret-manager.itscontained.io
+
+var _ webhook.Validator = &SecretStore{}
+
+// ValidateCreate implements webhook.Validator.
+func (c *SecretStore) ValidateCreate() error {
+	return validateStore(SecretStoreGroupVersionKind.GroupKind(), c)
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (c *SecretStore) ValidateUpdate(old runtime.Object) error {
+	return validateStore(SecretStoreGroupVersionKind.GroupKind(), c)
+}
+
+// ValidateDelete implements webhook.Validator.
+func (c *SecretStore) ValidateDelete() error {
+	return nil
+}
+
+func (c *ClusterSecretStore) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(c).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-secret-manager-itscontained-io-v1alpha1-clustersecretstore,mutating=true,failurePolicy=fail,groups=secret-manager.itscontained.io,resources=clustersecretstores,versions=v1alpha1,name=mclustersecretstore.secret-manager.itscontained.io
+
+var _ webhook.Defaulter = &ClusterSecretStore{}
+
+// Default implements webhook.Defaulter.
+func (c *ClusterSecretStore) Default() {
+	SetSecretStoreSpecDefaults(&c.Spec)
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-secret-manager-itscontained-io-v1alpha1-clustersecretstore,mutating=false,failurePolicy=fail,groups=secret-manager.itscontained.io,resources=clustersecretstores,versions=v1alpha1,name=vclustersecretstore.secret-manager.itscontained.io
+
+var _ webhook.Validator = &ClusterSecretStore{}
+
+// ValidateCreate implements webhook.Validator.
+func (c *ClusterSecretStore) ValidateCreate() error {
+	return validateStore(ClusterSecretStoreGroupVersionKind.GroupKind(), c)
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (c *ClusterSecretStore) ValidateUpdate(old runtime.Object) error {
+	return validateStore(ClusterSecretStoreGroupVersionKind.GroupKind(), c)
+}
+
+// ValidateDelete implements webhook.Validator.
+func (c *ClusterSecretStore) ValidateDelete() error {
+	return nil
+}
+
+// SetSecretStoreSpecDefaults sets the defaults of the configured backend which
+// are otherwise applied by the store implementation at runtime.
+func SetSecretStoreSpecDefaults(spec *SecretStoreSpec) {
+	if spec.AWS != nil && spec.AWS.Service == "" {
+		spec.AWS.Service = DefaultAWSService
+	}
+	if spec.Vault == nil {
+		return
+	}
+	if spec.Vault.Engine == "" {
+		spec.Vault.Engine = DefaultVaultEngine
+	}
+	if spec.Vault.Engine == VaultEngineKV && spec.Vault.Version == nil {
+		version := DefaultVaultKVEngineVersion
+		spec.Vault.Version = &version
+	}
+	// the defaulted KV version is kept when the engine of an existing store is
+	// changed to Dynamic, e.g. by kubectl apply, and would be rejected
+	if spec.Vault.Engine == VaultEngineDynamic && spec.Vault.Version != nil && *spec.Vault.Version == DefaultVaultKVEngineVersion {
+		spec.Vault.Version = nil
+	}
+	auth := &spec.Vault.Auth
+	if auth.AppRole != nil && auth.AppRole.Path == "" {
+		auth.AppRole.Path = DefaultVaultAppRoleAuthMountPath
+	}
+	if auth.Kubernetes != nil {
+		if auth.Kubernetes.Path == "" {
+			auth.Kubernetes.Path = DefaultVaultKubernetesAuthMountPath
+		}
+		if auth.Kubernetes.SecretRef != nil && auth.Kubernetes.SecretRef.Key == "" {
+			auth.Kubernetes.SecretRef.Key = DefaultVaultKubernetesAuthSecretKey
+		}
+	}
+	if auth.JWT != nil && auth.JWT.Path == "" {
+		auth.JWT.Path = DefaultVaultJWTAuthMountPath
+	}
+	if auth.Cert != nil && auth.Cert.Path == "" {
+		auth.Cert.Path = DefaultVaultCertAuthMountPath
+	}
+	if auth.UserPass != nil && auth.UserPass.Path == "" {
+		auth.UserPass.Path = DefaultVaultUserPassAuthMountPath
+	}
+	if auth.LDAP != nil && auth.LDAP.Path == "" {
+		auth.LDAP.Path = DefaultVaultLDAPAuthMountPath
+	}
+	if auth.IAM != nil && auth.IAM.Path == "" {
+		auth.IAM.Path = DefaultVaultIAMAuthMountPath
+	}
+}
+
+func validateStore(gk schema.GroupKind, store GenericStore) error {
+	errs := ValidateSecretStoreSpec(store.GetSpec(), field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(gk, store.GetName(), errs)
+}
+
+// ValidateSecretStoreSpec validates that exactly one backend is configured and
+// that the backend configuration is consistent.
+func ValidateSecretStoreSpec(spec *SecretStoreSpec, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	var backends []string
+	if spec.Vault != nil {
+		backends = append(backends, "vault")
+		errs = append(errs, validateVaultStore(spec.Vault, fldPath.Child("vault"))...)
+	}
+	if spec.AWS != nil {
+		backends = append(backends, "aws")
+		errs = append(errs, validateAWSStore(spec.AWS, fldPath.Child("aws"))...)
+	}
+	if spec.GCP != nil {
+		backends = append(backends, "gcp")
+		errs = append(errs, validateGCPStore(spec.GCP, fldPath.Child("gcp"))...)
+	}
+
+	return append(errs, validateOneOf(fldPath, backends, "store backend")...)
+}
+
+func validateVaultStore(spec *VaultStore, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if spec.Server == "" {
+		errs = append(errs, field.Required(fldPath.Child("server"), ""))
+	}
+	if spec.Path == "" {
+		errs = append(errs, field.Required(fldPath.Child("path"), ""))
+	}
+	if spec.Version != nil && spec.Engine == VaultEngineDynamic {
+		errs = append(errs, field.Forbidden(fldPath.Child("version"), "may not be set for the Dynamic engine"))
+	} else if spec.Version != nil {
+		switch *spec.Version {
+		case VaultKVStoreV1, VaultKVStoreV2:
+		default:
+			errs = append(errs, field.NotSupported(fldPath.Child("version"), *spec.Version,
+				[]string{string(VaultKVStoreV1), string(VaultKVStoreV2)}))
+		}
+	}
+	return append(errs, validateVaultAuth(&spec.Auth, fldPath.Child("auth"))...)
+}
+
+func validateVaultAuth(auth *VaultAuth, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	var methods []string
+	if auth.TokenSecretRef != nil {
+		methods = append(methods, "tokenSecretRef")
+		errs = append(errs, validateSecretKeySelector(auth.TokenSecretRef, fldPath.Child("tokenSecretRef"))...)
+	}
+	if auth.AppRole != nil {
+		methods = append(methods, "appRole")
+		fldPath := fldPath.Child("appRole")
+		if auth.AppRole.RoleID == "" {
+			errs = append(errs, field.Required(fldPath.Child("roleId"), ""))
+		}
+		errs = append(errs, validateSecretKeySelector(&auth.AppRole.SecretRef, fldPath.Child("secretRef"))...)
+	}
+	if auth.Kubernetes != nil {
+		methods = append(methods, "kubernetes")
+		fldPath := fldPath.Child("kubernetes")
+		if auth.Kubernetes.Role == "" {
+			errs = append(errs, field.Required(fldPath.Child("role"), ""))
+		}
+		if auth.Kubernetes.SecretRef != nil && auth.Kubernetes.SecretRef.Name == "" {
+			errs = append(errs, field.Required(fldPath.Child("secretRef", "name"), ""))
+		}
+		if auth.Kubernetes.ServiceAccountRef != nil {
+			errs = append(errs, validateVaultServiceAccountRef(auth.Kubernetes.ServiceAccountRef, fldPath.Child("serviceAccountRef"))...)
+			if auth.Kubernetes.SecretRef != nil {
+				errs = append(errs, field.Forbidden(fldPath.Child("serviceAccountRef"), "may not be combined with secretRef"))
+			}
+		}
+	}
+	if auth.JWT != nil {
+		methods = append(methods, "jwt")
+		errs = append(errs, validateVaultJWTAuth(auth.JWT, fldPath.Child("jwt"))...)
+	}
+	if auth.Cert != nil {
+		methods = append(methods, "cert")
+		fldPath := fldPath.Child("cert")
+		errs = append(errs, validateSecretKeySelector(&auth.Cert.ClientCert, fldPath.Child("clientCert"))...)
+		errs = append(errs, validateSecretKeySelector(&auth.Cert.SecretRef, fldPath.Child("secretRef"))...)
+	}
+	if auth.UserPass != nil {
+		methods = append(methods, "userPass")
+		errs = append(errs, validateVaultUserPassAuth(auth.UserPass.Username, &auth.UserPass.SecretRef, fldPath.Child("userPass"))...)
+	}
+	if auth.LDAP != nil {
+		methods = append(methods, "ldap")
+		errs = append(errs, validateVaultUserPassAuth(auth.LDAP.Username, &auth.LDAP.SecretRef, fldPath.Child("ldap"))...)
+	}
+	if auth.IAM != nil {
+		methods = append(methods, "iam")
+		fldPath := fldPath.Child("iam")
+		if auth.IAM.Role == "" {
+			errs = append(errs, field.Required(fldPath.Child("role"), ""))
+		}
+		if auth.IAM.AuthSecretRef != nil {
+			errs = append(errs, validateAWSAuth(auth.IAM.AuthSecretRef, fldPath.Child("authSecretRef"))...)
+		}
+	}
+	return append(errs, validateOneOf(fldPath, methods, "authentication method")...)
+}
+
+func validateVaultJWTAuth(auth *VaultJWTAuth, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	var sources []string
+	if auth.ServiceAccountRef != nil {
+		sources = append(sources, "serviceAccountRef")
+		errs = append(errs, validateVaultServiceAccountRef(auth.ServiceAccountRef, fldPath.Child("serviceAccountRef"))...)
+	}
+	if auth.SecretRef != nil {
+		sources = append(sources, "secretRef")
+		errs = append(errs, validateSecretKeySelector(auth.SecretRef, fldPath.Child("secretRef"))...)
+	}
+	return append(errs, validateOneOf(fldPath, sources, "token source")...)
+}
+
+// validateVaultServiceAccountRef validates a ServiceAccount whose tokens are
+// sent to the Vault server.
+func validateVaultServiceAccountRef(ref *smmeta.ServiceAccountSelector, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if ref.Name == "" {
+		errs = append(errs, field.Required(fldPath.Child("name"), ""))
+	}
+	for i, audience := range ref.Audiences {
+		if IsKubernetesAPIAudience(audience) {
+			errs = append(errs, field.Forbidden(fldPath.Child("audiences").Index(i), "tokens sent to Vault may not be issued for the Kubernetes API server"))
+		}
+	}
+	return errs
+}
+
+func validateVaultUserPassAuth(username string, secretRef *smmeta.SecretKeySelector, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if username == "" {
+		errs = append(errs, field.Required(fldPath.Child("username"), ""))
+	}
+	return append(errs, validateSecretKeySelector(secretRef, fldPath.Child("secretRef"))...)
+}
+
+func validateAWSStore(spec *AWSStore, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if spec.AuthSecretRef != nil {
+		errs = append(errs, validateAWSAuth(spec.AuthSecretRef, fldPath.Child("authSecretRef"))...)
+	}
+	// roles assumed with the credentials of another role are limited to
+	// sessions of one hour by AWS
+	maxDuration := maxAWSSessionDuration
+	if auth := spec.AuthSecretRef; auth != nil && (auth.JWT != nil || auth.Role != nil) {
+		maxDuration = maxAWSChainedSessionDuration
+	}
+	if spec.Role != "" || spec.ExternalID != "" || spec.SessionName != "" || len(spec.SessionTags) > 0 || spec.Duration != nil {
+		role := AWSAssumeRole{
+			Role:        spec.Role,
+			ExternalID:  spec.ExternalID,
+			SessionName: spec.SessionName,
+			SessionTags: spec.SessionTags,
+			Duration:    spec.Duration,
+		}
+		errs = append(errs, validateAWSAssumeRole(&role, maxDuration, fldPath)...)
+	}
+	for i := range spec.RoleChain {
+		errs = append(errs, validateAWSAssumeRole(&spec.RoleChain[i], maxAWSChainedSessionDuration, fldPath.Child("roleChain").Index(i))...)
+	}
+	return errs
+}
+
+// bounds of the duration of AWS role sessions
+const (
+	minAWSSessionDuration        = 15 * time.Minute
+	maxAWSSessionDuration        = 12 * time.Hour
+	maxAWSChainedSessionDuration = time.Hour
+)
+
+// awsSessionNameRegexp matches the role session names accepted by AWS.
+var awsSessionNameRegexp = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+func validateAWSAssumeRole(role *AWSAssumeRole, maxDuration time.Duration, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if role.Role == "" {
+		errs = append(errs, field.Required(fldPath.Child("role"), ""))
+	}
+	if role.SessionName != "" && !awsSessionNameRegexp.MatchString(role.SessionName) {
+		errs = append(errs, field.Invalid(fldPath.Child("sessionName"), role.SessionName,
+			"must be 2 to 64 characters of letters, digits and +=,.@_-"))
+	}
+	for i, tag := range role.SessionTags {
+		if tag.Key == "" {
+			errs = append(errs, field.Required(fldPath.Child("sessionTags").Index(i).Child("key"), ""))
+		}
+	}
+	if role.Duration != nil && (role.Duration.Duration < minAWSSessionDuration || role.Duration.Duration > maxDuration) {
+		errs = append(errs, field.Invalid(fldPath.Child("duration"), role.Duration.Duration.String(),
+			fmt.Sprintf("must be between %s and %s", minAWSSessionDuration, maxDuration)))
+	}
+	return errs
+}
+
+func validateAWSAuth(auth *AWSAuth, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if (auth.AccessKeyID == nil) != (auth.SecretAccessKey == nil) {
+		errs = append(errs, field.Invalid(fldPath, "",
+			"accessKeyID and secretAccessKey must be specified together"))
+	}
+	if auth.AccessKeyID != nil {
+		errs = append(errs, validateSecretKeySelector(auth.AccessKeyID, fldPath.Child("accessKeyID"))...)
+	}
+	if auth.SecretAccessKey != nil {
+		errs = append(errs, validateSecretKeySelector(auth.SecretAccessKey, fldPath.Child("secretAccessKey"))...)
+	}
+	if auth.Role != nil {
+		errs = append(errs, validateSecretKeySelector(auth.Role, fldPath.Child("role"))...)
+	}
+	if auth.JWT != nil {
+		fldPath := fldPath.Child("jwt")
+		if auth.AccessKeyID != nil || auth.SecretAccessKey != nil {
+			errs = append(errs, field.Forbidden(fldPath, "may not be combined with accessKeyID and secretAccessKey"))
+		}
+		if auth.JWT.ServiceAccountRef.Name == "" {
+			errs = append(errs, field.Required(fldPath.Child("serviceAccountRef", "name"), ""))
+		}
+		if auth.JWT.Role == "" {
+			errs = append(errs, field.Required(fldPath.Child("role"), ""))
+		}
+	}
+	return errs
+}
+
+func validateGCPStore(spec *GCPStore, fldPath *field.Path) field.ErrorList {
+	if spec.AuthSecretRef == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	var methods []string
+	auth := spec.AuthSecretRef
+	fldPath = fldPath.Child("authSecretRef")
+	if auth.JSON != nil {
+		methods = append(methods, "json")
+		errs = append(errs, validateSecretKeySelector(auth.JSON, fldPath.Child("json"))...)
+	}
+	if auth.FilePath != nil {
+		methods = append(methods, "filePath")
+		if *auth.FilePath == "" {
+			errs = append(errs, field.Required(fldPath.Child("filePath"), ""))
+		}
+	}
+	if len(methods) > 1 {
+		errs = append(errs, validateOneOf(fldPath, methods, "authentication method")...)
+	}
+	return errs
+}
+
+// validateOneOf returns an error if not exactly one of the named fields is set.
+func validateOneOf(fldPath *field.Path, fields []string, what string) field.ErrorList {
+	switch len(fields) {
+	case 0:
+		return field.ErrorList{field.Required(fldPath, "exactly one "+what+" must be specified")}
+	case 1:
+		return nil
+	}
+	var errs field.ErrorList
+	for _, f := range fields[1:] {
+		errs = append(errs, field.Forbidden(fldPath.Child(f),
+			"may not specify more than one "+what+", found "+fields[0]))
+	}
+	return errs
+}
+
+func validateSecretKeySelector(selector *smmeta.SecretKeySelector, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if selector.Name == "" {
+		errs = append(errs, field.Required(fldPath.Child("name"), ""))
+	}
+	if selector.Key == "" {
+		errs = append(errs, field.Required(fldPath.Child("key"), ""))
+	}
+	return errs
+}
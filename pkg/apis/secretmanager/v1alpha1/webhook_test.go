@@ -0,0 +1,510 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Defaulting webhooks", func() {
+	It("should materialize the vault defaults", func() {
+		store := &SecretStore{
+			Spec: SecretStoreSpec{
+				Vault: &VaultStore{
+					Auth: VaultAuth{
+						Kubernetes: &VaultKubernetesAuth{
+							Role: "role",
+							SecretRef: &smmeta.SecretKeySelector{
+								LocalObjectReference: smmeta.LocalObjectReference{Name: "vault-sa"},
+							},
+						},
+					},
+				},
+			},
+		}
+		store.Default()
+		Expect(store.Spec.Vault.Engine).To(Equal(VaultEngineKV))
+		Expect(*store.Spec.Vault.Version).To(Equal(VaultKVStoreV2))
+		Expect(store.Spec.Vault.Auth.Kubernetes.Path).To(Equal(DefaultVaultKubernetesAuthMountPath))
+		Expect(store.Spec.Vault.Auth.Kubernetes.SecretRef.Key).To(Equal("token"))
+
+		clusterStore := &ClusterSecretStore{
+			Spec: SecretStoreSpec{
+				Vault: &VaultStore{
+					Auth: VaultAuth{AppRole: &VaultAppRole{RoleID: "role"}},
+				},
+			},
+		}
+		clusterStore.Default()
+		Expect(clusterStore.Spec.Vault.Auth.AppRole.Path).To(Equal(DefaultVaultAppRoleAuthMountPath))
+
+		auth := VaultAuth{
+			JWT:      &VaultJWTAuth{},
+			Cert:     &VaultCertAuth{},
+			UserPass: &VaultUserPassAuth{},
+			LDAP:     &VaultLDAPAuth{},
+			IAM:      &VaultIAMAuth{},
+		}
+		SetSecretStoreSpecDefaults(&SecretStoreSpec{Vault: &VaultStore{Auth: auth}})
+		Expect(auth.JWT.Path).To(Equal(DefaultVaultJWTAuthMountPath))
+		Expect(auth.Cert.Path).To(Equal(DefaultVaultCertAuthMountPath))
+		Expect(auth.UserPass.Path).To(Equal(DefaultVaultUserPassAuthMountPath))
+		Expect(auth.LDAP.Path).To(Equal(DefaultVaultLDAPAuthMountPath))
+		Expect(auth.IAM.Path).To(Equal(DefaultVaultIAMAuthMountPath))
+	})
+
+	It("should not default the KV version of dynamic vault stores", func() {
+		store := &SecretStore{
+			Spec: SecretStoreSpec{
+				Vault: &VaultStore{Engine: VaultEngineDynamic},
+			},
+		}
+		store.Default()
+		Expect(store.Spec.Vault.Version).To(BeNil())
+	})
+
+	It("should remove the defaulted KV version when switching to the dynamic engine", func() {
+		store := &SecretStore{
+			Spec: SecretStoreSpec{
+				Vault: &VaultStore{
+					Server: "https://vault.example.com:8200",
+					Path:   "database",
+					Auth: VaultAuth{
+						TokenSecretRef: &smmeta.SecretKeySelector{
+							LocalObjectReference: smmeta.LocalObjectReference{Name: "vault-token"},
+							Key:                  "token",
+						},
+					},
+				},
+			},
+		}
+		store.Default()
+		Expect(*store.Spec.Vault.Version).To(Equal(DefaultVaultKVEngineVersion))
+
+		store.Spec.Vault.Engine = VaultEngineDynamic
+		store.Default()
+		Expect(store.Spec.Vault.Version).To(BeNil())
+		Expect(store.ValidateUpdate(store)).To(Succeed())
+	})
+
+	It("should default the AWS service", func() {
+		store := &SecretStore{Spec: SecretStoreSpec{AWS: &AWSStore{}}}
+		store.Default()
+		Expect(store.Spec.AWS.Service).To(Equal(AWSServiceSecretsManager))
+
+		store.Spec.AWS.Service = AWSServiceParameterStore
+		store.Default()
+		Expect(store.Spec.AWS.Service).To(Equal(AWSServiceParameterStore))
+	})
+
+	It("should not override explicit vault settings", func() {
+		version := VaultKVStoreV1
+		store := &SecretStore{
+			Spec: SecretStoreSpec{
+				Vault: &VaultStore{
+					Version: &version,
+					Auth:    VaultAuth{AppRole: &VaultAppRole{Path: "custom-approle"}},
+				},
+			},
+		}
+		store.Default()
+		Expect(*store.Spec.Vault.Version).To(Equal(VaultKVStoreV1))
+		Expect(store.Spec.Vault.Auth.AppRole.Path).To(Equal("custom-approle"))
+	})
+
+	It("should default the ExternalSecret store kind and target policies", func() {
+		spec := &ExternalSecretSpec{
+			StoreRef: ObjectReference{Name: "gcp"},
+			Data: []KeyReference{
+				{SecretKey: "a", RemoteRef: RemoteReference{Name: "a"}},
+			},
+			DataFrom: []RemoteReference{{Name: "c"}},
+		}
+		SetExternalSecretSpecDefaults(spec)
+		Expect(spec.StoreRef.Kind).To(Equal(SecretStoreKind))
+		Expect(spec.Target.CreationPolicy).To(Equal(CreatePolicyOwner))
+		Expect(spec.Target.DeletionPolicy).To(Equal(DeletionPolicyDelete))
+		// versions depend on the store backend and are defaulted when reading
+		Expect(spec.Data[0].RemoteRef.Version).To(BeNil())
+		Expect(spec.DataFrom[0].Version).To(BeNil())
+	})
+
+	It("should keep the store kind and default the deletion policy of merged secrets", func() {
+		spec := &ExternalSecretSpec{
+			StoreRef: ObjectReference{Name: "vault", Kind: ClusterSecretStoreKind},
+			DataFrom: []RemoteReference{{Name: "c"}},
+			Target:   ExternalSecretTarget{CreationPolicy: CreatePolicyMerge},
+		}
+		SetExternalSecretSpecDefaults(spec)
+		Expect(spec.StoreRef.Kind).To(Equal(ClusterSecretStoreKind))
+		Expect(spec.Target.DeletionPolicy).To(Equal(DeletionPolicyRetain))
+	})
+
+	It("should only default the template engine if a template is set", func() {
+		spec := &ExternalSecretSpec{StoreRef: ObjectReference{Name: "vault"}}
+		SetExternalSecretSpecDefaults(spec)
+		Expect(spec.TemplateEngine).To(BeEmpty())
+
+		spec.Template = []byte(`{}`)
+		SetExternalSecretSpecDefaults(spec)
+		Expect(spec.TemplateEngine).To(Equal(TemplateEngineNone))
+
+		spec = &ExternalSecretSpec{
+			StoreRef:     ObjectReference{Name: "vault"},
+			TemplateFrom: []TemplateFrom{{ConfigMap: &TemplateRef{Name: "templates"}}},
+		}
+		SetExternalSecretSpecDefaults(spec)
+		Expect(spec.TemplateEngine).To(Equal(TemplateEngineNone))
+	})
+})
+
+var _ = Describe("Validating webhooks", func() {
+	secretRef := func(name, key string) *smmeta.SecretKeySelector {
+		return &smmeta.SecretKeySelector{
+			LocalObjectReference: smmeta.LocalObjectReference{Name: name},
+			Key:                  key,
+		}
+	}
+	vaultStore := func() *SecretStore {
+		return &SecretStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault", Namespace: "default"},
+			Spec: SecretStoreSpec{
+				Vault: &VaultStore{
+					Server: "https://vault.example.com:8200",
+					Path:   "secret",
+					Auth: VaultAuth{
+						TokenSecretRef: secretRef("vault-token", "token"),
+					},
+				},
+			},
+		}
+	}
+
+	Context("SecretStore", func() {
+		It("should accept a valid store", func() {
+			Expect(vaultStore().ValidateCreate()).To(Succeed())
+		})
+
+		It("should reject a store without backend", func() {
+			store := vaultStore()
+			store.Spec.Vault = nil
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("exactly one store backend"))
+		})
+
+		It("should reject a store with multiple backends", func() {
+			store := vaultStore()
+			store.Spec.AWS = &AWSStore{}
+			err := store.ValidateUpdate(vaultStore())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.aws"))
+		})
+
+		It("should reject multiple vault auth methods", func() {
+			store := vaultStore()
+			store.Spec.Vault.Auth.Kubernetes = &VaultKubernetesAuth{Path: "kubernetes", Role: "role"}
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.kubernetes"))
+		})
+
+		It("should validate vault auth methods", func() {
+			store := vaultStore()
+			store.Spec.Vault.Auth = VaultAuth{
+				JWT: &VaultJWTAuth{
+					ServiceAccountRef: &smmeta.ServiceAccountSelector{Name: "team-a"},
+				},
+			}
+			Expect(store.ValidateCreate()).To(Succeed())
+
+			store.Spec.Vault.Auth.JWT.SecretRef = secretRef("vault-jwt", "jwt")
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.jwt.secretRef: Forbidden"))
+
+			store.Spec.Vault.Auth.JWT.SecretRef = nil
+			store.Spec.Vault.Auth.JWT.ServiceAccountRef.Audiences = []string{"vault", "https://kubernetes.default.svc"}
+			err = store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.jwt.serviceAccountRef.audiences[1]: Forbidden"))
+
+			store.Spec.Vault.Auth = VaultAuth{
+				Cert:     &VaultCertAuth{ClientCert: *secretRef("vault-cert", "tls.crt")},
+				UserPass: &VaultUserPassAuth{SecretRef: *secretRef("vault-user", "password")},
+				LDAP:     &VaultLDAPAuth{Username: "team-a"},
+				IAM:      &VaultIAMAuth{},
+			}
+			err = store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.cert.secretRef.name: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.userPass.username: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.ldap.secretRef.name: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.iam.role: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.userPass: Forbidden"))
+		})
+
+		It("should validate the vault kubernetes auth token source", func() {
+			store := vaultStore()
+			store.Spec.Vault.Auth = VaultAuth{
+				Kubernetes: &VaultKubernetesAuth{
+					Role:              "team-a",
+					ServiceAccountRef: &smmeta.ServiceAccountSelector{Name: "team-a"},
+				},
+			}
+			Expect(store.ValidateCreate()).To(Succeed())
+
+			store.Spec.Vault.Auth.Kubernetes.ServiceAccountRef.Name = ""
+			store.Spec.Vault.Auth.Kubernetes.SecretRef = secretRef("vault-secret", "token")
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.kubernetes.serviceAccountRef.name: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.kubernetes.serviceAccountRef: Forbidden"))
+
+			store.Spec.Vault.Auth.Kubernetes.SecretRef = nil
+			store.Spec.Vault.Auth.Kubernetes.ServiceAccountRef = &smmeta.ServiceAccountSelector{
+				Name:      "team-a",
+				Audiences: []string{"kubernetes.default.svc"},
+			}
+			err = store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.auth.kubernetes.serviceAccountRef.audiences[0]: Forbidden"))
+		})
+
+		It("should reject an unknown vault KV version", func() {
+			store := vaultStore()
+			version := VaultKVStoreVersion("v3")
+			store.Spec.Vault.Version = &version
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.version"))
+		})
+
+		It("should reject a KV version for dynamic vault stores", func() {
+			store := vaultStore()
+			store.Spec.Vault.Engine = VaultEngineDynamic
+			Expect(store.ValidateCreate()).To(Succeed())
+
+			version := VaultKVStoreV2
+			store.Spec.Vault.Version = &version
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.vault.version"))
+		})
+
+		It("should reject conflicting GCP auth methods", func() {
+			filePath := "/etc/gcp/credentials.json"
+			store := &ClusterSecretStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "gcp"},
+				Spec: SecretStoreSpec{
+					GCP: &GCPStore{
+						AuthSecretRef: &GCPAuth{
+							JSON:     secretRef("gcp-credentials", "credentials.json"),
+							FilePath: &filePath,
+						},
+					},
+				},
+			}
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.gcp.authSecretRef.filePath"))
+		})
+
+		It("should reject partial AWS static credentials", func() {
+			store := &SecretStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws", Namespace: "default"},
+				Spec: SecretStoreSpec{
+					AWS: &AWSStore{
+						AuthSecretRef: &AWSAuth{
+							AccessKeyID: secretRef("aws-credentials", "access-key-id"),
+						},
+					},
+				},
+			}
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("must be specified together"))
+		})
+
+		It("should validate AWS assume role options", func() {
+			store := &SecretStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws", Namespace: "default"},
+				Spec: SecretStoreSpec{
+					AWS: &AWSStore{
+						ExternalID:  "external-id",
+						Duration:    &metav1.Duration{Duration: time.Minute},
+						SessionName: "secret manager",
+						RoleChain: []AWSAssumeRole{
+							{Role: "arn:aws:iam::123456789012:role/team-a", SessionName: "team-a@sync"},
+							{SessionTags: []AWSSessionTag{{Value: "team-a"}}},
+							{
+								Role:     "arn:aws:iam::123456789012:role/team-b",
+								Duration: &metav1.Duration{Duration: 2 * time.Hour},
+							},
+						},
+					},
+				},
+			}
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.aws.role: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.aws.duration: Invalid"))
+			Expect(err.Error()).To(ContainSubstring("spec.aws.sessionName: Invalid"))
+			Expect(err.Error()).To(ContainSubstring("spec.aws.roleChain[1].role: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.aws.roleChain[1].sessionTags[0].key: Required"))
+			Expect(err.Error()).To(ContainSubstring("spec.aws.roleChain[2].duration: Invalid"))
+			Expect(err.Error()).NotTo(ContainSubstring("roleChain[0]"))
+		})
+
+		It("should limit the session duration of roles assumed with web identity credentials", func() {
+			store := &SecretStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws", Namespace: "default"},
+				Spec: SecretStoreSpec{
+					AWS: &AWSStore{
+						AuthSecretRef: &AWSAuth{
+							JWT: &AWSJWTAuth{
+								Role:              "arn:aws:iam::123456789012:role/broker",
+								ServiceAccountRef: smmeta.ServiceAccountSelector{Name: "secret-reader"},
+							},
+						},
+						Role:     "arn:aws:iam::123456789012:role/team-a",
+						Duration: &metav1.Duration{Duration: 2 * time.Hour},
+					},
+				},
+			}
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.aws.duration: Invalid"))
+
+			store.Spec.AWS.Duration = &metav1.Duration{Duration: time.Hour}
+			Expect(store.ValidateCreate()).To(Succeed())
+		})
+
+		It("should reject AWS JWT auth combined with static credentials", func() {
+			store := &SecretStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws", Namespace: "default"},
+				Spec: SecretStoreSpec{
+					AWS: &AWSStore{
+						AuthSecretRef: &AWSAuth{
+							AccessKeyID:     secretRef("aws-credentials", "access-key-id"),
+							SecretAccessKey: secretRef("aws-credentials", "secret-access-key"),
+							JWT: &AWSJWTAuth{
+								ServiceAccountRef: smmeta.ServiceAccountSelector{Name: "secret-reader"},
+							},
+						},
+					},
+				},
+			}
+			err := store.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.aws.authSecretRef.jwt: Forbidden"))
+			Expect(err.Error()).To(ContainSubstring("spec.aws.authSecretRef.jwt.role: Required"))
+		})
+	})
+
+	Context("ExternalSecret", func() {
+		externalSecret := func() *ExternalSecret {
+			return &ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "default"},
+				Spec: ExternalSecretSpec{
+					StoreRef: ObjectReference{Name: "vault"},
+					Data: []KeyReference{
+						{SecretKey: "username", RemoteRef: RemoteReference{Name: "db"}},
+						{SecretKey: "password", RemoteRef: RemoteReference{Name: "db"}},
+					},
+					Template: []byte(`{"metadata":{"annotations":{"team":"platform"}}}`),
+				},
+			}
+		}
+
+		It("should accept a valid ExternalSecret", func() {
+			Expect(externalSecret().ValidateCreate()).To(Succeed())
+		})
+
+		It("should reject duplicate secret keys", func() {
+			extSecret := externalSecret()
+			extSecret.Spec.Data[1].SecretKey = "username"
+			err := extSecret.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.data[1].secretKey"))
+		})
+
+		It("should reject an unparseable template", func() {
+			extSecret := externalSecret()
+			extSecret.Spec.Template = []byte(`{"metadata":{"annotations":"invalid type"}}`)
+			err := extSecret.ValidateUpdate(externalSecret())
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.template"))
+		})
+
+		It("should reject template sources without exactly one of configMap or secret", func() {
+			extSecret := externalSecret()
+			extSecret.Spec.TemplateFrom = []TemplateFrom{
+				{ConfigMap: &TemplateRef{Name: "templates"}},
+				{},
+				{ConfigMap: &TemplateRef{Name: "templates"}, Secret: &TemplateRef{}},
+			}
+			err := extSecret.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).NotTo(ContainSubstring("spec.templateFrom[0]"))
+			Expect(err.Error()).To(ContainSubstring("spec.templateFrom[1]"))
+			Expect(err.Error()).To(ContainSubstring("spec.templateFrom[2].secret"))
+			Expect(err.Error()).To(ContainSubstring("spec.templateFrom[2].secret.name"))
+		})
+
+		It("should reject an unknown store kind", func() {
+			extSecret := externalSecret()
+			extSecret.Spec.StoreRef.Kind = "Secret"
+			err := extSecret.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.storeRef.kind"))
+		})
+
+		It("should reject an invalid target", func() {
+			extSecret := externalSecret()
+			extSecret.Spec.Target.Name = "Invalid_Name"
+			extSecret.Spec.Target.Labels = map[string]string{"invalid label": "value"}
+			err := extSecret.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.target.name"))
+			Expect(err.Error()).To(ContainSubstring("spec.target.labels"))
+		})
+
+		It("should reject the Delete deletion policy for a merged target", func() {
+			extSecret := externalSecret()
+			extSecret.Spec.Target.CreationPolicy = CreatePolicyMerge
+			extSecret.Spec.Target.DeletionPolicy = DeletionPolicyDelete
+			err := extSecret.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.target.deletionPolicy"))
+		})
+
+		It("should reject a negative refresh interval", func() {
+			extSecret := externalSecret()
+			extSecret.Spec.RefreshInterval = &metav1.Duration{Duration: -time.Minute}
+			err := extSecret.ValidateCreate()
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("spec.refreshInterval"))
+		})
+	})
+})
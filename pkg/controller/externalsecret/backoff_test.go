@@ -0,0 +1,52 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalSecrets Backoff", func() {
+	r := &ExternalSecretReconciler{
+		BackoffBase: time.Second,
+		BackoffMax:  time.Minute,
+	}
+
+	It("should grow exponentially with jitter", func() {
+		for failures, expected := range map[int32]time.Duration{
+			1: time.Second,
+			2: 2 * time.Second,
+			3: 4 * time.Second,
+			6: 32 * time.Second,
+		} {
+			for i := 0; i < 10; i++ {
+				delay := r.backoff(failures)
+				Expect(delay).Should(BeNumerically(">=", expected/2), "failures: %d", failures)
+				Expect(delay).Should(BeNumerically("<=", expected), "failures: %d", failures)
+			}
+		}
+	})
+
+	It("should be capped at the maximum delay", func() {
+		for _, failures := range []int32{7, 10, 100, 1 << 30} {
+			delay := r.backoff(failures)
+			Expect(delay).Should(BeNumerically(">=", time.Minute/2))
+			Expect(delay).Should(BeNumerically("<=", time.Minute))
+		}
+	})
+})
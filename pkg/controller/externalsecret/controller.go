@@ -17,7 +17,10 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -27,32 +30,62 @@ import (
 	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
 	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
 	ctxlog "github.com/itscontained/secret-manager/pkg/log"
+	smmetrics "github.com/itscontained/secret-manager/pkg/metrics"
 	"github.com/itscontained/secret-manager/pkg/store"
 	_ "github.com/itscontained/secret-manager/pkg/store/register" // register known store backends
 	storeschema "github.com/itscontained/secret-manager/pkg/store/schema"
+	"github.com/itscontained/secret-manager/pkg/template"
 	"github.com/itscontained/secret-manager/pkg/util/merge"
 
 	corev1 "k8s.io/api/core/v1"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
+	"k8s.io/client-go/tools/record"
+
 	"k8s.io/utils/clock"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
-	ownerKey     = ".metadata.controller"
-	requeueAfter = time.Second * 30
+	ownerKey = ".metadata.controller"
+
+	defaultBackoffBase = time.Second * 5
+	defaultBackoffMax  = time.Minute * 5
+
+	// minRefreshDelay bounds the delay before re-syncing an ExternalSecret
+	// whose fetched secrets expire, to not sync secrets with short leases in
+	// a tight loop.
+	minRefreshDelay = time.Second * 5
 
 	errStoreNotFound       = "cannot get store reference"
 	errStoreSetupFailed    = "cannot setup store client"
 	errGetSecretDataFailed = "cannot get ExternalSecret data from store"
 	errTemplateFailed      = "failed to merge secret with template field"
+	errTargetConflict      = "cannot write target secret"
+)
+
+// Reasons of the events emitted for an ExternalSecret.
+const (
+	reasonStoreNotFound     = "StoreNotFound"
+	reasonStoreAuthFailed   = "StoreAuthFailed"
+	reasonSecretFetchFailed = "SecretFetchFailed"
+	reasonTemplateFailed    = "TemplateFailed"
+	reasonTargetConflict    = "TargetConflict"
+	reasonSyncFailed        = "SyncFailed"
+	reasonSynced            = "Synced"
+	reasonUpdated           = "Updated"
 )
 
 // ExternalSecretReconciler reconciles a ExternalSecret object
@@ -62,7 +95,22 @@ type ExternalSecretReconciler struct {
 	Scheme *runtime.Scheme
 	Clock  clock.Clock
 
-	Reader client.Reader
+	Reader   client.Reader
+	Recorder record.EventRecorder
+
+	// Namespace limits the controller to a single namespace, in which case
+	// ClusterSecretStores are not watched.
+	Namespace string
+
+	// DefaultRefreshInterval is used for ExternalSecrets which do not
+	// specify a refresh interval. A zero value disables periodic refresh.
+	DefaultRefreshInterval time.Duration
+
+	// BackoffBase is the delay before retrying the first failed sync of an
+	// ExternalSecret. The delay doubles with every consecutive failure.
+	BackoffBase time.Duration
+	// BackoffMax caps the delay between retries of failed syncs.
+	BackoffMax time.Duration
 }
 
 func (r *ExternalSecretReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -72,72 +120,188 @@ func (r *ExternalSecretReconciler) Reconcile(req ctrl.Request) (ctrl.Result, err
 
 	extSecret := &smv1alpha1.ExternalSecret{}
 	if err := r.Get(ctx, req.NamespacedName, extSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			smmetrics.ExternalSecrets.Delete(req.NamespacedName)
+		}
 		log.Error(err, "unable to get ExternalSecret")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !extSecret.DeletionTimestamp.IsZero() {
+		if err := r.finalize(ctx, extSecret); err != nil {
+			log.Error(err, "unable to finalize ExternalSecret")
+			return ctrl.Result{}, err
+		}
+		smmetrics.ExternalSecrets.Delete(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.updateFinalizer(ctx, extSecret); err != nil {
+		log.Error(err, "unable to update ExternalSecret finalizer")
+		return ctrl.Result{}, err
+	}
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      extSecret.Name,
+			Name:      targetSecretName(extSecret),
 			Namespace: extSecret.Namespace,
 		},
 	}
 
+	storeKind := smv1alpha1.SecretStoreKind
+	if extSecret.Spec.StoreRef.Kind == smv1alpha1.ClusterSecretStoreKind {
+		storeKind = smv1alpha1.ClusterSecretStoreKind
+	}
+	backend := ""
+	var refreshTime time.Time
+
+	wasReady := extSecret.Status.GetCondition(smmeta.TypeReady).Status == corev1.ConditionTrue
 	result, err := ctrl.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if err := checkTarget(extSecret, secret); err != nil {
+			return newSyncError(reasonTargetConflict, errTargetConflict, err)
+		}
+
+		target := &extSecret.Spec.Target
+		creationPolicy := target.GetCreationPolicy()
+		if creationPolicy == smv1alpha1.CreatePolicyOwner {
+			err := controllerutil.SetControllerReference(extSecret, &secret.ObjectMeta, r.Scheme)
+			if err != nil {
+				return fmt.Errorf("failed to set ExternalSecret controller reference: %w", err)
+			}
+		} else {
+			// the secret must not be garbage collected with the ExternalSecret
+			// once the creation policy changed from Owner
+			removeOwnerReference(secret, extSecret.UID)
+		}
+
+		// the data of an existing immutable secret cannot be updated
+		if !secret.CreationTimestamp.IsZero() && secret.Immutable != nil && *secret.Immutable {
+			log.V(1).Info("skipping update of immutable secret", "secret", secret.Name)
+			return nil
+		}
+
 		s, err := r.getStore(ctx, extSecret)
 		if err != nil {
-			return fmt.Errorf("%s: %w", errStoreNotFound, err)
+			return newSyncError(reasonStoreNotFound, errStoreNotFound, err)
+		}
+
+		backend, err = storeschema.GetStoreBackend(s.GetSpec())
+		if err != nil {
+			return newSyncError(reasonStoreAuthFailed, errStoreSetupFailed, err)
 		}
 
 		storeClient, err := storeschema.GetStore(s)
 		if err != nil {
-			return fmt.Errorf("%s: %w", errStoreSetupFailed, err)
+			return newSyncError(reasonStoreAuthFailed, errStoreSetupFailed, err)
 		}
 
 		storeClient, err = storeClient.New(ctx, s, r.Client, req.Namespace)
 		if err != nil {
-			return fmt.Errorf("%s: %w", errStoreSetupFailed, err)
+			return newSyncError(reasonStoreAuthFailed, errStoreSetupFailed, err)
 		}
+		refresher, _ := storeClient.(store.Refresher)
+		storeClient = smmetrics.InstrumentStoreClient(backend, storeClient)
 
-		err = controllerutil.SetControllerReference(extSecret, &secret.ObjectMeta, r.Scheme)
+		data, err := r.getSecret(ctx, storeClient, extSecret)
 		if err != nil {
-			return fmt.Errorf("failed to set ExternalSecret controller reference: %w", err)
+			return newSyncError(reasonSecretFetchFailed, errGetSecretDataFailed, err)
+		}
+		if refresher != nil {
+			refreshTime = refresher.RefreshTime()
 		}
 
-		secret.Labels = extSecret.Labels
-		secret.Annotations = extSecret.Annotations
-		secret.Data, err = r.getSecret(ctx, storeClient, extSecret)
-		if err != nil {
-			return fmt.Errorf("%s: %w", errGetSecretDataFailed, err)
+		labels, annotations := extSecret.Labels, extSecret.Annotations
+		if target.Labels != nil {
+			labels = target.Labels
+		}
+		if target.Annotations != nil {
+			annotations = target.Annotations
+		}
+		if creationPolicy == smv1alpha1.CreatePolicyMerge {
+			secret.Labels = merge.MergeStringMap(secret.Labels, labels)
+			secret.Annotations = merge.MergeStringMap(secret.Annotations, annotations)
+			if secret.Data == nil {
+				secret.Data = make(map[string][]byte, len(data))
+			}
+			secret.Data = merge.Merge(secret.Data, data)
+		} else {
+			secret.Labels = labels
+			secret.Annotations = annotations
+			secret.Data = data
+		}
+		if target.Type != "" {
+			secret.Type = target.Type
+		}
+		if target.Immutable != nil {
+			secret.Immutable = target.Immutable
 		}
 
-		if extSecret.Spec.Template != nil {
-			err = r.templateSecret(secret, extSecret.Spec.Template)
+		if extSecret.Spec.Template != nil || len(extSecret.Spec.TemplateFrom) > 0 {
+			err = r.templateSecret(ctx, secret, extSecret, data)
 			if err != nil {
-				return fmt.Errorf("%s: %w", errTemplateFailed, err)
+				return newSyncError(reasonTemplateFailed, errTemplateFailed, err)
 			}
 		}
 
+		if creationPolicy == smv1alpha1.CreatePolicyNone {
+			// copy the annotations, which may be shared with the ExternalSecret
+			secret.Annotations = merge.MergeStringMap(merge.MergeStringMap(nil, secret.Annotations), map[string]string{
+				smv1alpha1.ManagedSecretAnnotation: extSecret.Name,
+			})
+		}
 		return nil
 	})
+	smmetrics.SyncCalls.WithLabelValues(storeKind, backend, smmetrics.Result(err)).Inc()
 
 	if err != nil {
-		log.Error(err, "error while reconciling ExternalSecret")
+		smmetrics.ExternalSecrets.SetFailed(req.NamespacedName)
+		extSecret.Status.FailedSyncs++
+		retryAfter := r.backoff(extSecret.Status.FailedSyncs)
+		log.Error(err, "error while reconciling ExternalSecret", "failures", extSecret.Status.FailedSyncs, "retryAfter", retryAfter)
+		r.Recorder.Event(extSecret, corev1.EventTypeWarning, syncErrorReason(err), smmeta.Capitalize(err.Error()))
 		extSecret.Status.SetConditions(smmeta.Unavailable().WithMessage(err.Error()))
 		_ = r.Status().Update(ctx, extSecret)
-		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		return ctrl.Result{RequeueAfter: retryAfter}, nil
+	}
+
+	if err := r.releasePreviousTarget(ctx, extSecret); err != nil {
+		log.Error(err, "unable to release previous target secret", "secret", extSecret.Status.TargetName)
+		return ctrl.Result{}, err
 	}
 
 	log.Info("successfully reconcile ExternalSecret", "operation", result)
+	switch result {
+	case controllerutil.OperationResultCreated:
+		r.Recorder.Eventf(extSecret, corev1.EventTypeNormal, reasonSynced, "Created Secret %q", secret.Name)
+	case controllerutil.OperationResultUpdated:
+		r.Recorder.Eventf(extSecret, corev1.EventTypeNormal, reasonUpdated, "Updated Secret %q", secret.Name)
+	default:
+		if !wasReady {
+			r.Recorder.Eventf(extSecret, corev1.EventTypeNormal, reasonSynced, "Secret %q is in sync", secret.Name)
+		}
+	}
 	extSecret.Status.SetConditions(smmeta.Available())
+	extSecret.Status.RefreshTime = metav1.NewTime(r.Clock.Now())
+	extSecret.Status.FailedSyncs = 0
+	extSecret.Status.TargetName = secret.Name
+	smmetrics.ExternalSecrets.SetSynced(req.NamespacedName, extSecret.Status.RefreshTime.Time)
 	_ = r.Status().Update(ctx, extSecret)
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: r.nextRefresh(extSecret, refreshTime)}, nil
 }
 
 func (r *ExternalSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if r.Clock == nil {
 		r.Clock = clock.RealClock{}
 	}
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("secret-manager")
+	}
+	if r.BackoffBase <= 0 {
+		r.BackoffBase = defaultBackoffBase
+	}
+	if r.BackoffMax <= 0 {
+		r.BackoffMax = defaultBackoffMax
+	}
 
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Secret{}, ownerKey, func(rawObj runtime.Object) []string {
 		secret := rawObj.(*corev1.Secret)
@@ -153,10 +317,133 @@ func (r *ExternalSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}); err != nil {
 		return err
 	}
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&smv1alpha1.ExternalSecret{}).
+	if err := r.setupIndexes(mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+
+	// the manager client is backed by the informer cache and supports the field indexes
+	reader := mgr.GetClient()
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&smv1alpha1.ExternalSecret{}, builder.WithPredicates(ignoreStatusUpdates())).
 		Owns(&corev1.Secret{}).
-		Complete(r)
+		Watches(&source.Kind{Type: &smv1alpha1.SecretStore{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.externalSecretsForStore(reader, smv1alpha1.SecretStoreKind),
+		}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.externalSecretsForSecret(reader),
+		}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.externalSecretsForConfigMap(reader),
+		})
+	if r.Namespace == "" {
+		b = b.Watches(&source.Kind{Type: &smv1alpha1.ClusterSecretStore{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.externalSecretsForStore(reader, smv1alpha1.ClusterSecretStoreKind),
+		})
+	}
+	return b.Complete(r)
+}
+
+// targetSecretName returns the name of the secret generated for the
+// ExternalSecret.
+func targetSecretName(extSecret *smv1alpha1.ExternalSecret) string {
+	if extSecret.Spec.Target.Name != "" {
+		return extSecret.Spec.Target.Name
+	}
+	return extSecret.Name
+}
+
+// refreshInterval returns the interval after which the ExternalSecret should
+// be synced again.
+func (r *ExternalSecretReconciler) refreshInterval(extSecret *smv1alpha1.ExternalSecret) time.Duration {
+	if extSecret.Spec.RefreshInterval != nil {
+		return extSecret.Spec.RefreshInterval.Duration
+	}
+	return r.DefaultRefreshInterval
+}
+
+// nextRefresh returns the delay until the next sync of the ExternalSecret,
+// which is the refresh interval unless the fetched secrets have to be fetched
+// again earlier, e.g. to renew the lease of dynamic secrets.
+func (r *ExternalSecretReconciler) nextRefresh(extSecret *smv1alpha1.ExternalSecret, refreshTime time.Time) time.Duration {
+	interval := r.refreshInterval(extSecret)
+	if refreshTime.IsZero() {
+		return interval
+	}
+	delay := refreshTime.Sub(r.Clock.Now())
+	if delay < minRefreshDelay {
+		delay = minRefreshDelay
+	}
+	if interval == 0 || delay < interval {
+		return delay
+	}
+	return interval
+}
+
+// syncError annotates an error which occurred while syncing an ExternalSecret
+// with the reason of the event emitted for it.
+type syncError struct {
+	reason string
+	err    error
+}
+
+func newSyncError(reason, msg string, err error) error {
+	return &syncError{
+		reason: reason,
+		err:    fmt.Errorf("%s: %w", msg, err),
+	}
+}
+
+func (e *syncError) Error() string {
+	return e.err.Error()
+}
+
+func (e *syncError) Unwrap() error {
+	return e.err
+}
+
+// syncErrorReason returns the event reason for an error returned while
+// syncing an ExternalSecret.
+func syncErrorReason(err error) string {
+	var serr *syncError
+	if errors.As(err, &serr) {
+		return serr.reason
+	}
+	return reasonSyncFailed
+}
+
+// backoff returns the delay before retrying an ExternalSecret which failed to
+// sync the given number of consecutive times. The delay grows exponentially
+// from BackoffBase up to BackoffMax, with a random jitter of up to half the
+// delay so that ExternalSecrets failing at the same time do not retry in lockstep.
+func (r *ExternalSecretReconciler) backoff(failures int32) time.Duration {
+	delay := r.BackoffBase
+	for i := int32(1); i < failures && delay < r.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > r.BackoffMax {
+		delay = r.BackoffMax
+	}
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// ignoreStatusUpdates filters out update events which only change the status
+// of an ExternalSecret, as every successful sync updates the refresh time.
+func ignoreStatusUpdates() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.MetaOld == nil || e.MetaNew == nil {
+				return true
+			}
+			return e.MetaNew.GetDeletionTimestamp() != nil ||
+				e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration() ||
+				!reflect.DeepEqual(e.MetaOld.GetLabels(), e.MetaNew.GetLabels()) ||
+				!reflect.DeepEqual(e.MetaOld.GetAnnotations(), e.MetaNew.GetAnnotations())
+		},
+	}
 }
 
 func (r *ExternalSecretReconciler) getSecret(ctx context.Context, storeClient store.Client, extSecret *smv1alpha1.ExternalSecret) (map[string][]byte, error) {
@@ -166,6 +453,10 @@ func (r *ExternalSecretReconciler) getSecret(ctx context.Context, storeClient st
 		if err != nil {
 			return nil, fmt.Errorf("name %q: %w", remoteRef.Name, err)
 		}
+		secretMap, err = decodeMap(remoteRef.DecodingStrategy, secretMap)
+		if err != nil {
+			return nil, fmt.Errorf("name %q: %w", remoteRef.Name, err)
+		}
 		secretDataMap = merge.Merge(secretDataMap, secretMap)
 	}
 
@@ -174,6 +465,10 @@ func (r *ExternalSecretReconciler) getSecret(ctx context.Context, storeClient st
 		if err != nil {
 			return nil, fmt.Errorf("name %q: %w", secretRef.RemoteRef.Name, err)
 		}
+		secretData, err = decode(secretRef.RemoteRef.DecodingStrategy, secretData)
+		if err != nil {
+			return nil, fmt.Errorf("name %q: %w", secretRef.RemoteRef.Name, err)
+		}
 		secretDataMap[secretRef.SecretKey] = secretData
 	}
 
@@ -202,11 +497,93 @@ func (r *ExternalSecretReconciler) getStore(ctx context.Context, extSecret *smv1
 	return secretStore, nil
 }
 
-func (r *ExternalSecretReconciler) templateSecret(secret *corev1.Secret, template []byte) error {
+func (r *ExternalSecretReconciler) templateSecret(ctx context.Context, secret *corev1.Secret, extSecret *smv1alpha1.ExternalSecret, data map[string][]byte) error {
+	spec := &extSecret.Spec
 	templatedSecret := &corev1.Secret{}
-	if err := json.Unmarshal(template, templatedSecret); err != nil {
-		return fmt.Errorf("error unmarshalling json: %w", err)
+	if spec.Template != nil {
+		if err := json.Unmarshal(spec.Template, templatedSecret); err != nil {
+			return fmt.Errorf("error unmarshalling json: %w", err)
+		}
+	}
+
+	files, err := r.templateFiles(ctx, extSecret)
+	if err != nil {
+		return err
+	}
+	for k, v := range files {
+		if _, ok := templatedSecret.Data[k]; ok {
+			continue
+		}
+		if _, ok := templatedSecret.StringData[k]; ok {
+			continue
+		}
+		if templatedSecret.StringData == nil {
+			templatedSecret.StringData = make(map[string]string, len(files))
+		}
+		templatedSecret.StringData[k] = v
+	}
+
+	if spec.TemplateEngine == smv1alpha1.TemplateEngineGo {
+		if err := template.Execute(templatedSecret, data); err != nil {
+			return err
+		}
+	}
+	// stringData is write-only, merging it would update the secret on every sync
+	for k, v := range templatedSecret.StringData {
+		if templatedSecret.Data == nil {
+			templatedSecret.Data = make(map[string][]byte, len(templatedSecret.StringData))
+		}
+		templatedSecret.Data[k] = []byte(v)
 	}
+	templatedSecret.StringData = nil
 
 	return mergo.Merge(secret, templatedSecret, mergo.WithOverride)
 }
+
+// templateFiles returns the template files of the ConfigMaps and Secrets
+// referenced in the templateFrom field, later references taking precedence.
+func (r *ExternalSecretReconciler) templateFiles(ctx context.Context, extSecret *smv1alpha1.ExternalSecret) (map[string]string, error) {
+	files := make(map[string]string)
+	for i, from := range extSecret.Spec.TemplateFrom {
+		if (from.ConfigMap == nil) == (from.Secret == nil) {
+			return nil, fmt.Errorf("templateFrom[%d] must reference exactly one of a ConfigMap or a Secret", i)
+		}
+
+		var kind string
+		var ref *smv1alpha1.TemplateRef
+		values := make(map[string]string)
+		switch {
+		case from.ConfigMap != nil:
+			kind, ref = configMapKind, from.ConfigMap
+			configMap := &corev1.ConfigMap{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: extSecret.Namespace, Name: ref.Name}, configMap); err != nil {
+				return nil, fmt.Errorf("unable to get template ConfigMap %q: %w", ref.Name, err)
+			}
+			values = configMap.Data
+		default:
+			kind, ref = secretKind, from.Secret
+			secret := &corev1.Secret{}
+			if err := r.Get(ctx, types.NamespacedName{Namespace: extSecret.Namespace, Name: ref.Name}, secret); err != nil {
+				return nil, fmt.Errorf("unable to get template Secret %q: %w", ref.Name, err)
+			}
+			for k, v := range secret.Data {
+				values[k] = string(v)
+			}
+		}
+
+		if len(ref.Keys) == 0 {
+			for k, v := range values {
+				files[k] = v
+			}
+			continue
+		}
+		for _, k := range ref.Keys {
+			v, ok := values[k]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found in template %s %q", k, kind, ref.Name)
+			}
+			files[k] = v
+		}
+	}
+	return files, nil
+}
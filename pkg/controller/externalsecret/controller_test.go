@@ -23,12 +23,14 @@ import (
 	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
 	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
 	storeint "github.com/itscontained/secret-manager/pkg/store"
+	fakestore "github.com/itscontained/secret-manager/pkg/store/fake"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -102,6 +104,16 @@ var _ = Describe("ExternalSecrets Controller", func() {
 				return fetchedCond.Matches(smmeta.Unavailable()) &&
 					matches(fetchedCond.Message, errStoreNotFound)
 			}, timeout, interval).Should(BeTrue())
+
+			Eventually(func() bool {
+				By("Fetching the ExternalSecret events successfully")
+				events := &corev1.EventList{}
+				Expect(k8sClient.List(context.Background(), events, client.InNamespace(key.Namespace), client.MatchingFields{
+					"involvedObject.name": key.Name,
+					"reason":              reasonStoreNotFound,
+				})).Should(Succeed())
+				return len(events.Items) > 0 && events.Items[0].Type == corev1.EventTypeWarning
+			}, timeout, interval).Should(BeTrue(), "A StoreNotFound event should be recorded")
 		})
 
 		It("An ExternalSecret referencing a SecretStore with invalid credentials should be NotReady", func() {
@@ -251,6 +263,238 @@ var _ = Describe("ExternalSecrets Controller", func() {
 				"The secret should have annotations of the ExternalSecret")
 		})
 
+		It("An ExternalSecret with a refresh interval should be re-synced", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+			spec := smv1alpha1.ExternalSecretSpec{
+				StoreRef: smv1alpha1.ObjectReference{
+					Name: store.Name,
+					Kind: smv1alpha1.SecretStoreKind,
+				},
+				Data: []smv1alpha1.KeyReference{
+					{
+						SecretKey: "key",
+						RemoteRef: smv1alpha1.RemoteReference{
+							Name:     "secret/data/foo",
+							Property: smmeta.String("key"),
+						},
+					},
+				},
+				RefreshInterval: &metav1.Duration{Duration: time.Second},
+			}
+
+			key := types.NamespacedName{
+				Name:      secretType.Name,
+				Namespace: secretType.Namespace,
+			}
+
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: spec,
+			}
+
+			storeFactory.WithGetSecret([]byte("initial-value"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() bool {
+				By("Fetching the Secret successfully")
+				if err := k8sClient.Get(context.Background(), key, fetchedSecret); err != nil {
+					return false
+				}
+				return string(fetchedSecret.Data["key"]) == "initial-value"
+			}, timeout, interval).Should(BeTrue(), "The generated secret should be created")
+
+			fetched := &smv1alpha1.ExternalSecret{}
+			Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+			Expect(fetched.Status.RefreshTime.IsZero()).Should(BeFalse(), "The refresh time should be set")
+
+			By("Rotating the secret in the store")
+			storeFactory.WithGetSecret([]byte("rotated-value"), nil)
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+				return string(fetchedSecret.Data["key"]) == "rotated-value"
+			}, timeout, interval).Should(BeTrue(), "The generated secret should be updated")
+		})
+
+		It("An ExternalSecret should be re-synced when its SecretStore changes", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+			spec := smv1alpha1.ExternalSecretSpec{
+				StoreRef: smv1alpha1.ObjectReference{
+					Name: store.Name,
+					Kind: smv1alpha1.SecretStoreKind,
+				},
+				Data: []smv1alpha1.KeyReference{
+					{
+						SecretKey: "key",
+						RemoteRef: smv1alpha1.RemoteReference{
+							Name:     "secret/data/foo",
+							Property: smmeta.String("key"),
+						},
+					},
+				},
+			}
+
+			key := types.NamespacedName{
+				Name:      secretType.Name,
+				Namespace: secretType.Namespace,
+			}
+
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: spec,
+			}
+
+			storeFactory.WithGetSecret([]byte("old-store-value"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() bool {
+				By("Fetching the Secret successfully")
+				if err := k8sClient.Get(context.Background(), key, fetchedSecret); err != nil {
+					return false
+				}
+				return string(fetchedSecret.Data["key"]) == "old-store-value"
+			}, timeout, interval).Should(BeTrue(), "The generated secret should be created")
+
+			By("Updating the SecretStore successfully")
+			storeFactory.WithGetSecret([]byte("new-store-value"), nil)
+			fetchedStore := &smv1alpha1.SecretStore{}
+			Expect(k8sClient.Get(context.Background(), types.NamespacedName{Name: store.Name, Namespace: store.Namespace}, fetchedStore)).Should(Succeed())
+			fetchedStore.Spec.Vault.Server = "http://localhost:54321"
+			Expect(k8sClient.Update(context.Background(), fetchedStore)).Should(Succeed())
+
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+				return string(fetchedSecret.Data["key"]) == "new-store-value"
+			}, timeout, interval).Should(BeTrue(), "The generated secret should be updated")
+		})
+
+		It("An ExternalSecret should be re-synced when a Secret referenced by its SecretStore changes", func() {
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rotated-vault-token",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{"token": []byte("old-token")},
+			}
+			By("Creating the credentials Secret successfully")
+			Expect(k8sClient.Create(context.Background(), credentials)).Should(Succeed())
+			defer func() {
+				By("Deleting the credentials Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), credentials)).Should(Succeed())
+			}()
+
+			store := sampleStore.DeepCopy()
+			store.Name = "rotated-credentials"
+			store.Spec.Vault.Auth.TokenSecretRef = &smmeta.SecretKeySelector{
+				LocalObjectReference: smmeta.LocalObjectReference{Name: credentials.Name},
+				Key:                  "token",
+			}
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "rotated-credentials",
+				Namespace: "default",
+			}
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "key",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+				},
+			}
+
+			// the fake store returns the token it was set up with as secret value
+			storeFactory.WithNew(func(ctx context.Context, _ smv1alpha1.GenericStore,
+				kube client.Client, namespace string) (storeint.Client, error) {
+				secret := &corev1.Secret{}
+				if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: credentials.Name}, secret); err != nil {
+					return nil, err
+				}
+				return fakestore.New().WithGetSecret(secret.Data["token"], nil), nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() string {
+				if err := k8sClient.Get(context.Background(), key, fetchedSecret); err != nil {
+					return ""
+				}
+				return string(fetchedSecret.Data["key"])
+			}, timeout, interval).Should(Equal("old-token"), "The generated secret should be created")
+
+			By("Rotating the credentials Secret")
+			credentials.Data["token"] = []byte("new-token")
+			Expect(k8sClient.Update(context.Background(), credentials)).Should(Succeed())
+
+			Eventually(func() string {
+				Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+				return string(fetchedSecret.Data["key"])
+			}, timeout, interval).Should(Equal("new-token"), "The generated secret should be updated")
+		})
+
 		It("An ExternalSecret with dataFrom specified should generate secret", func() {
 			store := sampleStore.DeepCopy()
 			By("Creating the SecretStore successfully")
@@ -503,6 +747,769 @@ var _ = Describe("ExternalSecrets Controller", func() {
 					matches(fetchedCond.Message, errTemplateFailed)
 			}, timeout, interval).Should(BeTrue(), "The ExternalSecret should have a NotReady condition")
 		})
+
+		It("An ExternalSecret should only merge into an existing unmanaged Secret if allowed", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "unmanaged-secret",
+				Namespace: secretType.Namespace,
+			}
+			existing := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Data: map[string][]byte{
+					"existing": []byte("existing-value"),
+				},
+			}
+			By("Creating the unmanaged Secret successfully")
+			Expect(k8sClient.Create(context.Background(), existing)).Should(Succeed())
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), existing)).Should(Succeed())
+			}()
+
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "key",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("this-is-a-secret"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			fetched := &smv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Unavailable()) &&
+					matches(fetchedCond.Message, errTargetConflict)
+			}, timeout, interval).Should(BeTrue(), "The ExternalSecret should not take over the Secret")
+
+			By("Changing the creation policy to Merge")
+			Eventually(func() error {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetched.Spec.Target.CreationPolicy = smv1alpha1.CreatePolicyMerge
+				return k8sClient.Update(context.Background(), fetched)
+			}, timeout, interval).Should(Succeed())
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+				return string(fetchedSecret.Data["key"]) == "this-is-a-secret"
+			}, timeout, interval).Should(BeTrue(), "The fetched data should be merged into the Secret")
+			Expect(string(fetchedSecret.Data["existing"])).Should(Equal("existing-value"),
+				"The existing data of the Secret should be kept")
+			Expect(fetchedSecret.OwnerReferences).Should(BeEmpty(), "The Secret should not be owned by the ExternalSecret")
+		})
+
+		It("An ExternalSecret with creation policy None should not overwrite an unmanaged Secret", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "unmanaged-secret-none",
+				Namespace: secretType.Namespace,
+			}
+			existing := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Data: map[string][]byte{
+					"existing": []byte("existing-value"),
+				},
+			}
+			By("Creating the unmanaged Secret successfully")
+			Expect(k8sClient.Create(context.Background(), existing)).Should(Succeed())
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), existing)).Should(Succeed())
+			}()
+
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "key",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+					Target: smv1alpha1.ExternalSecretTarget{
+						CreationPolicy: smv1alpha1.CreatePolicyNone,
+						DeletionPolicy: smv1alpha1.DeletionPolicyDelete,
+					},
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("this-is-a-secret"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+
+			fetched := &smv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Unavailable()) &&
+					matches(fetchedCond.Message, errTargetConflict)
+			}, timeout, interval).Should(BeTrue(), "The ExternalSecret should not take over the Secret")
+
+			fetchedSecret := &corev1.Secret{}
+			Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+			Expect(fetchedSecret.Data).Should(Equal(existing.Data), "The Secret should not be overwritten")
+
+			By("Deleting the ExternalSecret successfully")
+			Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), key, &smv1alpha1.ExternalSecret{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue(), "The ExternalSecret should be finalized")
+			Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed(),
+				"The unmanaged Secret should not be deleted")
+		})
+
+		It("An ExternalSecret should release its Secret when the creation policy changes from Owner", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "policy-switch-secret",
+				Namespace: secretType.Namespace,
+			}
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "key",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("this-is-a-secret"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() bool {
+				if err := k8sClient.Get(context.Background(), key, fetchedSecret); err != nil {
+					return false
+				}
+				return len(fetchedSecret.OwnerReferences) == 1
+			}, timeout, interval).Should(BeTrue(), "The generated secret should be owned by the ExternalSecret")
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), fetchedSecret)).Should(Succeed())
+			}()
+
+			By("Changing the creation policy to None")
+			fetched := &smv1alpha1.ExternalSecret{}
+			Eventually(func() error {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetched.Spec.Target.CreationPolicy = smv1alpha1.CreatePolicyNone
+				return k8sClient.Update(context.Background(), fetched)
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+				return len(fetchedSecret.OwnerReferences) == 0
+			}, timeout, interval).Should(BeTrue(), "The owner reference should be removed")
+			Expect(fetchedSecret.Annotations[smv1alpha1.ManagedSecretAnnotation]).Should(Equal(key.Name),
+				"The Secret should be marked as managed by the ExternalSecret")
+			Expect(string(fetchedSecret.Data["key"])).Should(Equal("this-is-a-secret"))
+		})
+
+		It("An ExternalSecret with deletion policy Retain should release its Secret on deletion", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "retained-secret",
+				Namespace: secretType.Namespace,
+			}
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "key",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+					Target: smv1alpha1.ExternalSecretTarget{
+						DeletionPolicy: smv1alpha1.DeletionPolicyRetain,
+					},
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("this-is-a-secret"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() bool {
+				if err := k8sClient.Get(context.Background(), key, fetchedSecret); err != nil {
+					return false
+				}
+				return len(fetchedSecret.OwnerReferences) == 1
+			}, timeout, interval).Should(BeTrue(), "The generated secret should be created")
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), fetchedSecret)).Should(Succeed())
+			}()
+
+			By("Deleting the ExternalSecret successfully")
+			Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), key, &smv1alpha1.ExternalSecret{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue(), "The ExternalSecret should be finalized")
+
+			Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+			Expect(fetchedSecret.OwnerReferences).Should(BeEmpty(), "The owner reference should be removed")
+			Expect(fetchedSecret.Annotations[smv1alpha1.RetainedSecretAnnotation]).Should(Equal(key.Name),
+				"The Secret should be marked as retained")
+		})
+
+		It("An ExternalSecret with deletion policy Orphan should not adopt its Secret when re-created", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "orphaned-secret-none",
+				Namespace: secretType.Namespace,
+			}
+			newExternalSecret := func() *smv1alpha1.ExternalSecret {
+				return &smv1alpha1.ExternalSecret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      key.Name,
+						Namespace: key.Namespace,
+					},
+					Spec: smv1alpha1.ExternalSecretSpec{
+						StoreRef: smv1alpha1.ObjectReference{
+							Name: store.Name,
+							Kind: smv1alpha1.SecretStoreKind,
+						},
+						Data: []smv1alpha1.KeyReference{
+							{
+								SecretKey: "key",
+								RemoteRef: smv1alpha1.RemoteReference{
+									Name: "secret/data/foo",
+								},
+							},
+						},
+						Target: smv1alpha1.ExternalSecretTarget{
+							CreationPolicy: smv1alpha1.CreatePolicyNone,
+							DeletionPolicy: smv1alpha1.DeletionPolicyOrphan,
+						},
+					},
+				}
+			}
+			deleteExternalSecret := func(extSecret *smv1alpha1.ExternalSecret) {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), extSecret)).Should(Succeed())
+				Eventually(func() bool {
+					err := k8sClient.Get(context.Background(), key, &smv1alpha1.ExternalSecret{})
+					return apierrors.IsNotFound(err)
+				}, timeout, interval).Should(BeTrue(), "The ExternalSecret should be finalized")
+			}
+
+			storeFactory.WithGetSecret([]byte("this-is-a-secret"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			toCreate := newExternalSecret()
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() bool {
+				if err := k8sClient.Get(context.Background(), key, fetchedSecret); err != nil {
+					return false
+				}
+				return fetchedSecret.Annotations[smv1alpha1.ManagedSecretAnnotation] == key.Name
+			}, timeout, interval).Should(BeTrue(), "The generated secret should be marked as managed")
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), fetchedSecret)).Should(Succeed())
+			}()
+
+			deleteExternalSecret(toCreate)
+			Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+			Expect(fetchedSecret.Annotations).ShouldNot(HaveKey(smv1alpha1.ManagedSecretAnnotation),
+				"The managed annotation should be removed")
+
+			By("Re-creating the ExternalSecret successfully")
+			storeFactory.WithGetSecret([]byte("this-is-another-secret"), nil)
+			recreated := newExternalSecret()
+			Expect(k8sClient.Create(context.Background(), recreated)).Should(Succeed())
+			defer deleteExternalSecret(recreated)
+
+			fetched := &smv1alpha1.ExternalSecret{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Unavailable()) &&
+					matches(fetchedCond.Message, errTargetConflict)
+			}, timeout, interval).Should(BeTrue(), "The ExternalSecret should not adopt the orphaned Secret")
+			Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+			Expect(string(fetchedSecret.Data["key"])).Should(Equal("this-is-a-secret"),
+				"The orphaned Secret should not be overwritten")
+		})
+
+		It("An ExternalSecret with a target should generate a Secret with the target properties", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "target-secret",
+				Namespace: secretType.Namespace,
+			}
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+					Labels: map[string]string{
+						"externalsecret-label": "value",
+					},
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: corev1.BasicAuthUsernameKey,
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+					Target: smv1alpha1.ExternalSecretTarget{
+						Name:      "basic-auth",
+						Type:      corev1.SecretTypeBasicAuth,
+						Immutable: func(b bool) *bool { return &b }(true),
+						Labels: map[string]string{
+							"target-label": "value",
+						},
+					},
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("admin"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			secretKey := types.NamespacedName{
+				Name:      "basic-auth",
+				Namespace: key.Namespace,
+			}
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), secretKey, fetchedSecret)
+			}, timeout, interval).Should(Succeed(), "The Secret should be created with the target name")
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), fetchedSecret)).Should(Succeed())
+			}()
+
+			Expect(fetchedSecret.Type).Should(Equal(corev1.SecretTypeBasicAuth))
+			Expect(fetchedSecret.Immutable).ShouldNot(BeNil())
+			Expect(*fetchedSecret.Immutable).Should(BeTrue())
+			Expect(string(fetchedSecret.Data[corev1.BasicAuthUsernameKey])).Should(Equal("admin"))
+			Expect(fetchedSecret.Labels).Should(Equal(toCreate.Spec.Target.Labels),
+				"The Secret should only have the target labels")
+		})
+
+		It("An ExternalSecret should delete its previous Secret when the target name changes", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "renamed-target",
+				Namespace: secretType.Namespace,
+			}
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "key",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+					Target: smv1alpha1.ExternalSecretTarget{
+						Name: "old-target",
+					},
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("this-is-a-secret"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			oldKey := types.NamespacedName{Name: "old-target", Namespace: key.Namespace}
+			fetched := &smv1alpha1.ExternalSecret{}
+			Eventually(func() string {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				return fetched.Status.TargetName
+			}, timeout, interval).Should(Equal(oldKey.Name), "The Secret should be created with the target name")
+			Expect(k8sClient.Get(context.Background(), oldKey, &corev1.Secret{})).Should(Succeed())
+
+			By("Changing the target name")
+			Eventually(func() error {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetched.Spec.Target.Name = "new-target"
+				return k8sClient.Update(context.Background(), fetched)
+			}, timeout, interval).Should(Succeed())
+
+			newKey := types.NamespacedName{Name: "new-target", Namespace: key.Namespace}
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), newKey, fetchedSecret)
+			}, timeout, interval).Should(Succeed(), "The Secret should be created with the new target name")
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), fetchedSecret)).Should(Succeed())
+			}()
+
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), oldKey, &corev1.Secret{})
+				return apierrors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue(), "The Secret of the previous target name should be deleted")
+		})
+
+		It("An ExternalSecret with the Go template engine should render the Secret data", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			templateObject := map[string]interface{}{
+				"stringData": map[string]interface{}{
+					"url": "https://{{ .username }}@example.com",
+				},
+				"metadata": map[string]interface{}{
+					"annotations": map[string]interface{}{
+						"user": "{{ .username | upper }}",
+					},
+				},
+			}
+			templateObjectBytes, err := json.Marshal(templateObject)
+			Expect(err).Should(BeNil())
+
+			key := types.NamespacedName{
+				Name:      "go-template",
+				Namespace: secretType.Namespace,
+			}
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "username",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/foo",
+							},
+						},
+					},
+					Template:       templateObjectBytes,
+					TemplateEngine: smv1alpha1.TemplateEngineGo,
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("admin"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), key, fetchedSecret)
+			}, timeout, interval).Should(Succeed(), "The generated secret should be created")
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), fetchedSecret)).Should(Succeed())
+			}()
+
+			Expect(fetchedSecret.Data).Should(Equal(map[string][]byte{
+				"username": []byte("admin"),
+				"url":      []byte("https://admin@example.com"),
+			}))
+			Expect(fetchedSecret.Annotations).Should(Equal(map[string]string{"user": "ADMIN"}))
+
+			By("Referencing a missing key in the template")
+			fetched := &smv1alpha1.ExternalSecret{}
+			Eventually(func() error {
+				if err := k8sClient.Get(context.Background(), key, fetched); err != nil {
+					return err
+				}
+				fetched.Spec.Template = []byte(`{"stringData":{"url":"{{ .missing }}"}}`)
+				return k8sClient.Update(context.Background(), fetched)
+			}, timeout, interval).Should(Succeed())
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Unavailable()) &&
+					matches(fetchedCond.Message, errTemplateFailed)
+			}, timeout, interval).Should(BeTrue(), "The ExternalSecret should report the render error")
+		})
+
+		It("An ExternalSecret with a template ConfigMap should be re-synced when the ConfigMap changes", func() {
+			store := sampleStore.DeepCopy()
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "templates",
+					Namespace: secretType.Namespace,
+				},
+				Data: map[string]string{
+					".npmrc":  "//registry.example.com/:_authToken={{ .token }}",
+					"ignored": "{{ .missing }}",
+				},
+			}
+			By("Creating the template ConfigMap successfully")
+			Expect(k8sClient.Create(context.Background(), configMap)).Should(Succeed())
+			defer func() {
+				By("Deleting the template ConfigMap successfully")
+				Expect(k8sClient.Delete(context.Background(), configMap)).Should(Succeed())
+			}()
+
+			key := types.NamespacedName{
+				Name:      "npmrc",
+				Namespace: secretType.Namespace,
+			}
+			toCreate := &smv1alpha1.ExternalSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+				},
+				Spec: smv1alpha1.ExternalSecretSpec{
+					StoreRef: smv1alpha1.ObjectReference{
+						Name: store.Name,
+						Kind: smv1alpha1.SecretStoreKind,
+					},
+					Data: []smv1alpha1.KeyReference{
+						{
+							SecretKey: "token",
+							RemoteRef: smv1alpha1.RemoteReference{
+								Name: "secret/data/npm",
+							},
+						},
+					},
+					TemplateEngine: smv1alpha1.TemplateEngineGo,
+					TemplateFrom: []smv1alpha1.TemplateFrom{
+						{
+							ConfigMap: &smv1alpha1.TemplateRef{
+								Name: configMap.Name,
+								Keys: []string{".npmrc"},
+							},
+						},
+					},
+				},
+			}
+
+			storeFactory.WithGetSecret([]byte("t0k3n"), nil)
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the ExternalSecret successfully")
+			Expect(k8sClient.Create(context.Background(), toCreate)).Should(Succeed())
+			defer func() {
+				By("Deleting the ExternalSecret successfully")
+				Expect(k8sClient.Delete(context.Background(), toCreate)).Should(Succeed())
+			}()
+
+			fetchedSecret := &corev1.Secret{}
+			Eventually(func() string {
+				if err := k8sClient.Get(context.Background(), key, fetchedSecret); err != nil {
+					return ""
+				}
+				return string(fetchedSecret.Data[".npmrc"])
+			}, timeout, interval).Should(Equal("//registry.example.com/:_authToken=t0k3n"))
+			defer func() {
+				By("Deleting the Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), fetchedSecret)).Should(Succeed())
+			}()
+			Expect(fetchedSecret.Data).ShouldNot(HaveKey("ignored"), "Only the selected template keys should be used")
+
+			By("Updating the template ConfigMap")
+			configMap.Data[".npmrc"] = "//npm.example.com/:_authToken={{ .token }}"
+			Expect(k8sClient.Update(context.Background(), configMap)).Should(Succeed())
+			Eventually(func() string {
+				Expect(k8sClient.Get(context.Background(), key, fetchedSecret)).Should(Succeed())
+				return string(fetchedSecret.Data[".npmrc"])
+			}, timeout, interval).Should(Equal("//npm.example.com/:_authToken=t0k3n"),
+				"The Secret should be rendered with the updated template")
+		})
 	})
 })
 
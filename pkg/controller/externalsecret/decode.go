@@ -0,0 +1,82 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+)
+
+// decode applies the decoding strategy to a value fetched from the store.
+func decode(strategy smv1alpha1.DecodingStrategy, value []byte) ([]byte, error) {
+	switch strategy {
+	case "", smv1alpha1.DecodingStrategyNone:
+		return value, nil
+	case smv1alpha1.DecodingStrategyBase64:
+		return decodeBase64(value, base64.StdEncoding, base64.RawStdEncoding)
+	case smv1alpha1.DecodingStrategyBase64URL:
+		return decodeBase64(value, base64.URLEncoding, base64.RawURLEncoding)
+	case smv1alpha1.DecodingStrategyHex:
+		value = bytes.TrimSpace(value)
+		out := make([]byte, hex.DecodedLen(len(value)))
+		if _, err := hex.Decode(out, value); err != nil {
+			return nil, fmt.Errorf("unable to decode hex: %w", err)
+		}
+		return out, nil
+	case smv1alpha1.DecodingStrategyAuto:
+		if out, err := decode(smv1alpha1.DecodingStrategyBase64, value); err == nil {
+			return out, nil
+		}
+		if out, err := decode(smv1alpha1.DecodingStrategyBase64URL, value); err == nil {
+			return out, nil
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unknown decoding strategy %q", strategy)
+}
+
+// decodeMap applies the decoding strategy to every value of the map. The
+// values are decoded into a new map, as the map may be owned by the store.
+func decodeMap(strategy smv1alpha1.DecodingStrategy, values map[string][]byte) (map[string][]byte, error) {
+	decoded := make(map[string][]byte, len(values))
+	for k, v := range values {
+		out, err := decode(strategy, v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		decoded[k] = out
+	}
+	return decoded, nil
+}
+
+// decodeBase64 decodes the value with the padded encoding if it is padded and
+// with the raw encoding otherwise.
+func decodeBase64(value []byte, padded, raw *base64.Encoding) ([]byte, error) {
+	value = bytes.TrimSpace(value)
+	enc := raw
+	if len(value)%4 == 0 {
+		enc = padded
+	}
+	out := make([]byte, enc.DecodedLen(len(value)))
+	n, err := enc.Decode(out, value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode base64: %w", err)
+	}
+	return out[:n], nil
+}
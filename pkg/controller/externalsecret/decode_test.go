@@ -0,0 +1,73 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalSecrets Decoding", func() {
+	binary := []byte{0xfb, 0xff, 0x00, 'a'}
+
+	It("should decode the supported encodings", func() {
+		for strategy, encoded := range map[smv1alpha1.DecodingStrategy]string{
+			smv1alpha1.DecodingStrategyBase64:    "+/8AYQ==",
+			smv1alpha1.DecodingStrategyBase64URL: "-_8AYQ",
+			smv1alpha1.DecodingStrategyHex:       "fbff0061\n",
+			smv1alpha1.DecodingStrategyAuto:      "+/8AYQ",
+		} {
+			out, err := decode(strategy, []byte(encoded))
+			Expect(err).ShouldNot(HaveOccurred(), "strategy: %s", strategy)
+			Expect(out).Should(Equal(binary), "strategy: %s", strategy)
+		}
+		out, err := decode(smv1alpha1.DecodingStrategyAuto, []byte("-_8AYQ=="))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).Should(Equal(binary))
+	})
+
+	It("should keep values as-is without strategy", func() {
+		for _, strategy := range []smv1alpha1.DecodingStrategy{"", smv1alpha1.DecodingStrategyNone} {
+			out, err := decode(strategy, []byte("+/8AYQ=="))
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(out).Should(Equal([]byte("+/8AYQ==")))
+		}
+	})
+
+	It("should keep values which are not base64 with strategy Auto", func() {
+		out, err := decode(smv1alpha1.DecodingStrategyAuto, []byte("not base64!"))
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).Should(Equal([]byte("not base64!")))
+	})
+
+	It("should not modify the decoded map", func() {
+		values := map[string][]byte{"key": []byte("dmFsdWU=")}
+		out, err := decodeMap(smv1alpha1.DecodingStrategyBase64, values)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(out).Should(Equal(map[string][]byte{"key": []byte("value")}))
+		Expect(values).Should(Equal(map[string][]byte{"key": []byte("dmFsdWU=")}))
+	})
+
+	It("should fail on invalid values", func() {
+		_, err := decode(smv1alpha1.DecodingStrategyBase64, []byte("-_8AYQ"))
+		Expect(err).Should(HaveOccurred())
+		_, err = decode(smv1alpha1.DecodingStrategyHex, []byte("xyz"))
+		Expect(err).Should(HaveOccurred())
+		_, err = decodeMap(smv1alpha1.DecodingStrategyBase64, map[string][]byte{"key": []byte("!")})
+		Expect(err).Should(MatchError(ContainSubstring(`key "key"`)))
+	})
+})
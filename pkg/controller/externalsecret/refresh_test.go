@@ -0,0 +1,61 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/utils/clock"
+)
+
+var _ = Describe("ExternalSecrets Refresh", func() {
+	r := &ExternalSecretReconciler{
+		Clock:                  clock.RealClock{},
+		DefaultRefreshInterval: time.Hour,
+	}
+
+	It("should use the refresh interval for secrets without expiry", func() {
+		Expect(r.nextRefresh(&smv1alpha1.ExternalSecret{}, time.Time{})).To(Equal(time.Hour))
+	})
+
+	It("should refresh expiring secrets before the refresh interval", func() {
+		delay := r.nextRefresh(&smv1alpha1.ExternalSecret{}, time.Now().Add(10*time.Minute))
+		Expect(delay).To(BeNumerically("~", 10*time.Minute, time.Second))
+
+		delay = r.nextRefresh(&smv1alpha1.ExternalSecret{}, time.Now().Add(2*time.Hour))
+		Expect(delay).To(Equal(time.Hour))
+	})
+
+	It("should refresh expiring secrets if periodic refresh is disabled", func() {
+		extSecret := &smv1alpha1.ExternalSecret{
+			Spec: smv1alpha1.ExternalSecretSpec{
+				RefreshInterval: &metav1.Duration{},
+			},
+		}
+		delay := r.nextRefresh(extSecret, time.Now().Add(2*time.Hour))
+		Expect(delay).To(BeNumerically("~", 2*time.Hour, time.Second))
+	})
+
+	It("should not refresh expired secrets in a tight loop", func() {
+		Expect(r.nextRefresh(&smv1alpha1.ExternalSecret{}, time.Now().Add(-time.Minute))).To(Equal(minRefreshDelay))
+	})
+})
@@ -15,23 +15,17 @@ limitations under the License.
 package controllers
 
 import (
-	"path/filepath"
 	"strings"
 	"testing"
 
-	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
-	sm1valpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/controller/testenv"
 	fakestore "github.com/itscontained/secret-manager/pkg/store/fake"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
-	"k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/rest"
-
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/envtest/printer"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -41,9 +35,8 @@ import (
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
 var (
-	cfg          *rest.Config
 	k8sClient    client.Client
-	testEnv      *envtest.Environment
+	testEnv      *testenv.Environment
 	storeFactory *fakestore.Client
 )
 
@@ -59,46 +52,23 @@ var _ = BeforeSuite(func(done Done) {
 	logf.SetLogger(zap.LoggerTo(GinkgoWriter, true))
 
 	By("Bootstrapping test environment")
-	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "..", "..", "deploy", "crds")},
-	}
-
 	var err error
-	cfg, err = testEnv.Start()
-	Expect(err).ToNot(HaveOccurred())
-	Expect(cfg).ToNot(BeNil())
-
-	err = sm1valpha1.AddToScheme(scheme.Scheme)
-	Expect(err).NotTo(HaveOccurred())
-	err = smmeta.AddToScheme(scheme.Scheme)
-	Expect(err).NotTo(HaveOccurred())
-
-	k8sManager, err := ctrl.NewManager(cfg, ctrl.Options{
-		Scheme: scheme.Scheme,
-	})
-	Expect(err).ToNot(HaveOccurred())
-
-	// do not use mgr.GetClient()
-	// see https://github.com/kubernetes-sigs/controller-runtime/issues/343#issuecomment-469435686
-	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	testEnv, err = testenv.Start()
 	Expect(err).ToNot(HaveOccurred())
-	Expect(k8sClient).ToNot(BeNil())
+	k8sClient = testEnv.Client
+	storeFactory = testEnv.StoreFactory
 
-	storeFactory = fakestore.New()
-	storeFactory.RegisterAs(&sm1valpha1.SecretStoreSpec{
-		Vault: &sm1valpha1.VaultStore{},
-	})
 	err = (&ExternalSecretReconciler{
 		Client: k8sClient,
-		Reader: k8sManager.GetAPIReader(),
-		Scheme: k8sManager.GetScheme(),
+		Reader: testEnv.Manager.GetAPIReader(),
+		Scheme: testEnv.Manager.GetScheme(),
 		Log:    ctrl.Log.WithName("controllers").WithName("ExternalSecrets"),
-	}).SetupWithManager(k8sManager)
+	}).SetupWithManager(testEnv.Manager)
 	Expect(err).ToNot(HaveOccurred())
 
 	go func() {
 		defer GinkgoRecover()
-		Expect(k8sManager.Start(ctrl.SetupSignalHandler())).ToNot(HaveOccurred())
+		Expect(testEnv.Manager.Start(ctrl.SetupSignalHandler())).ToNot(HaveOccurred())
 	}()
 
 	close(done)
@@ -106,8 +76,9 @@ var _ = BeforeSuite(func(done Done) {
 
 var _ = AfterSuite(func() {
 	By("Tearing down the test environment")
-	err := testEnv.Stop()
-	Expect(err).ToNot(HaveOccurred())
+	if testEnv != nil {
+		Expect(testEnv.Stop()).To(Succeed())
+	}
 })
 
 func matches(s, substr string) bool {
@@ -0,0 +1,196 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// secretFinalizer is added to ExternalSecrets whose deletion policy cannot be
+// implemented by the garbage collector alone.
+const secretFinalizer = "secret-manager.itscontained.io/secret"
+
+// needsFinalizer returns whether the generated secret must be handled by the
+// controller when the ExternalSecret is deleted. Secrets owned by the
+// ExternalSecret are deleted by the garbage collector, secrets merged into are
+// never deleted. All other policies leave references on the secret, the owner
+// reference or the managed annotation, which have to be removed or replaced.
+func needsFinalizer(target *smv1alpha1.ExternalSecretTarget) bool {
+	creationPolicy := target.GetCreationPolicy()
+	switch target.GetDeletionPolicy() {
+	case smv1alpha1.DeletionPolicyDelete:
+		return creationPolicy == smv1alpha1.CreatePolicyNone
+	case smv1alpha1.DeletionPolicyOrphan:
+		return true
+	default:
+		return creationPolicy != smv1alpha1.CreatePolicyMerge
+	}
+}
+
+// updateFinalizer adds or removes the finalizer of the ExternalSecret
+// depending on its target policies.
+func (r *ExternalSecretReconciler) updateFinalizer(ctx context.Context, extSecret *smv1alpha1.ExternalSecret) error {
+	needed := needsFinalizer(&extSecret.Spec.Target)
+	if needed == controllerutil.ContainsFinalizer(extSecret, secretFinalizer) {
+		return nil
+	}
+	if needed {
+		controllerutil.AddFinalizer(extSecret, secretFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(extSecret, secretFinalizer)
+	}
+	return r.Update(ctx, extSecret)
+}
+
+// finalize applies the deletion policy of the ExternalSecret to the generated
+// secret and removes the finalizer.
+func (r *ExternalSecretReconciler) finalize(ctx context.Context, extSecret *smv1alpha1.ExternalSecret) error {
+	if !controllerutil.ContainsFinalizer(extSecret, secretFinalizer) {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: extSecret.Namespace, Name: targetSecretName(extSecret)}, secret)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil {
+		if err := r.releaseSecret(ctx, extSecret, secret); err != nil {
+			return err
+		}
+	}
+	if err := r.releasePreviousTarget(ctx, extSecret); err != nil {
+		return err
+	}
+
+	controllerutil.RemoveFinalizer(extSecret, secretFinalizer)
+	return r.Update(ctx, extSecret)
+}
+
+func (r *ExternalSecretReconciler) releaseSecret(ctx context.Context, extSecret *smv1alpha1.ExternalSecret, secret *corev1.Secret) error {
+	policy := extSecret.Spec.Target.GetDeletionPolicy()
+	if policy == smv1alpha1.DeletionPolicyDelete {
+		// never delete a secret which was not created by the ExternalSecret,
+		// e.g. because it could not be adopted
+		if !isManagedBy(secret, extSecret) {
+			return nil
+		}
+		return client.IgnoreNotFound(r.Delete(ctx, secret))
+	}
+
+	removeOwnerReference(secret, extSecret.UID)
+	if policy == smv1alpha1.DeletionPolicyOrphan && secret.Annotations[smv1alpha1.ManagedSecretAnnotation] == extSecret.Name {
+		delete(secret.Annotations, smv1alpha1.ManagedSecretAnnotation)
+	}
+	if policy == smv1alpha1.DeletionPolicyRetain && extSecret.Spec.Target.GetCreationPolicy() == smv1alpha1.CreatePolicyOwner {
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string)
+		}
+		secret.Annotations[smv1alpha1.RetainedSecretAnnotation] = extSecret.Name
+	}
+	return r.Update(ctx, secret)
+}
+
+// releasePreviousTarget applies the deletion policy of the ExternalSecret to
+// the secret generated for a previous target name.
+func (r *ExternalSecretReconciler) releasePreviousTarget(ctx context.Context, extSecret *smv1alpha1.ExternalSecret) error {
+	name := extSecret.Status.TargetName
+	if name == "" || name == targetSecretName(extSecret) {
+		return nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Namespace: extSecret.Namespace, Name: name}, secret)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	return r.releaseSecret(ctx, extSecret, secret)
+}
+
+// checkTarget verifies that the existing secret, if any, may be written
+// according to the creation policy of the ExternalSecret. Secrets created by
+// the ExternalSecret or retained by a deleted ExternalSecret of the same name
+// are adopted, other secrets are only written with the Merge policy.
+func checkTarget(extSecret *smv1alpha1.ExternalSecret, secret *corev1.Secret) error {
+	exists := !secret.CreationTimestamp.IsZero()
+	target := &extSecret.Spec.Target
+	if target.GetCreationPolicy() == smv1alpha1.CreatePolicyMerge {
+		if !exists {
+			return fmt.Errorf("secret %q does not exist and creation policy is %s", secret.Name, smv1alpha1.CreatePolicyMerge)
+		}
+		if target.GetDeletionPolicy() == smv1alpha1.DeletionPolicyDelete {
+			return fmt.Errorf("deletion policy %s cannot be used with creation policy %s, as secret %q is not created by the ExternalSecret",
+				smv1alpha1.DeletionPolicyDelete, smv1alpha1.CreatePolicyMerge, secret.Name)
+		}
+		return nil
+	}
+	if !exists || adoptSecret(extSecret, secret) {
+		return nil
+	}
+	return fmt.Errorf("secret %q already exists and is not managed by the ExternalSecret, "+
+		"use creation policy %s to merge into it", secret.Name, smv1alpha1.CreatePolicyMerge)
+}
+
+// adoptSecret returns whether the existing secret is managed by the
+// ExternalSecret and removes the references left by a deleted ExternalSecret
+// of the same name.
+func adoptSecret(extSecret *smv1alpha1.ExternalSecret, secret *corev1.Secret) bool {
+	if isManagedBy(secret, extSecret) {
+		return true
+	}
+	owner := metav1.GetControllerOf(secret)
+	if owner == nil && secret.Annotations[smv1alpha1.RetainedSecretAnnotation] == extSecret.Name {
+		delete(secret.Annotations, smv1alpha1.RetainedSecretAnnotation)
+		return true
+	}
+	// the secret is controlled by a deleted ExternalSecret of the same name
+	// and has not been garbage collected yet
+	if owner != nil && owner.Kind == smv1alpha1.ExtSecretKind && owner.Name == extSecret.Name &&
+		owner.APIVersion == smv1alpha1.ExtSecretGroupVersionKind.GroupVersion().String() {
+		removeOwnerReference(secret, owner.UID)
+		return true
+	}
+	return false
+}
+
+// isManagedBy returns whether the secret was created by the ExternalSecret,
+// either with or without owner reference.
+func isManagedBy(secret *corev1.Secret, extSecret *smv1alpha1.ExternalSecret) bool {
+	if metav1.IsControlledBy(secret, extSecret) {
+		return true
+	}
+	return metav1.GetControllerOf(secret) == nil && secret.Annotations[smv1alpha1.ManagedSecretAnnotation] == extSecret.Name
+}
+
+func removeOwnerReference(obj metav1.Object, uid types.UID) {
+	var refs []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID != uid {
+			refs = append(refs, ref)
+		}
+	}
+	obj.SetOwnerReferences(refs)
+}
@@ -0,0 +1,188 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/controller/index"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// storeRefKey indexes ExternalSecrets by the kind and name of the
+	// referenced store.
+	storeRefKey = ".spec.storeRef"
+	// templateRefKey indexes ExternalSecrets by the kind and name of the
+	// ConfigMaps and Secrets referenced in their templateFrom field.
+	templateRefKey = ".spec.templateFrom"
+
+	configMapKind = "ConfigMap"
+	secretKind    = "Secret"
+)
+
+// setupIndexes registers the field indexes used to find the ExternalSecrets
+// affected by a change of a store or of a template source. Finding the stores
+// referencing a Secret relies on the index.StoreSecretRefKey index, which is
+// shared with the store controllers and registered with the manager.
+func (r *ExternalSecretReconciler) setupIndexes(indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(context.Background(), &smv1alpha1.ExternalSecret{}, storeRefKey, func(rawObj runtime.Object) []string {
+		extSecret := rawObj.(*smv1alpha1.ExternalSecret)
+		return []string{storeRefIndexValue(storeRefKind(extSecret.Spec.StoreRef), extSecret.Spec.StoreRef.Name)}
+	}); err != nil {
+		return err
+	}
+	if err := indexer.IndexField(context.Background(), &smv1alpha1.ExternalSecret{}, templateRefKey, func(rawObj runtime.Object) []string {
+		return templateRefs(rawObj.(*smv1alpha1.ExternalSecret))
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// externalSecretsForStore maps a store of the given kind to the
+// ExternalSecrets referencing it.
+func (r *ExternalSecretReconciler) externalSecretsForStore(reader client.Reader, kind string) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		opts := []client.ListOption{
+			client.MatchingFields{storeRefKey: storeRefIndexValue(kind, obj.Meta.GetName())},
+		}
+		if kind == smv1alpha1.SecretStoreKind {
+			opts = append(opts, client.InNamespace(obj.Meta.GetNamespace()))
+		}
+		return r.externalSecretRequests(reader, opts...)
+	}
+}
+
+// externalSecretsForConfigMap maps a ConfigMap to the ExternalSecrets using
+// it as template.
+func (r *ExternalSecretReconciler) externalSecretsForConfigMap(reader client.Reader) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		return r.externalSecretRequests(reader,
+			client.InNamespace(obj.Meta.GetNamespace()),
+			client.MatchingFields{templateRefKey: templateRefIndexValue(configMapKind, obj.Meta.GetName())})
+	}
+}
+
+// externalSecretsForSecret maps a Kubernetes Secret to the ExternalSecrets
+// using it as template or whose store references the Secret, e.g. for
+// authentication.
+func (r *ExternalSecretReconciler) externalSecretsForSecret(reader client.Reader) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		ctx := context.Background()
+		ref := types.NamespacedName{
+			Namespace: obj.Meta.GetNamespace(),
+			Name:      obj.Meta.GetName(),
+		}
+
+		requests := r.externalSecretRequests(reader,
+			client.InNamespace(ref.Namespace),
+			client.MatchingFields{templateRefKey: templateRefIndexValue(secretKind, ref.Name)})
+
+		secretStores := &smv1alpha1.SecretStoreList{}
+		if err := reader.List(ctx, secretStores, client.InNamespace(ref.Namespace), client.MatchingFields{index.StoreSecretRefKey: index.SecretRefValue(ref)}); err != nil {
+			r.Log.Error(err, "unable to list SecretStores referencing secret", "secret", ref)
+			return requests
+		}
+		for _, store := range secretStores.Items {
+			requests = append(requests, r.externalSecretRequests(reader,
+				client.InNamespace(ref.Namespace),
+				client.MatchingFields{storeRefKey: storeRefIndexValue(smv1alpha1.SecretStoreKind, store.Name)})...)
+		}
+
+		if r.Namespace != "" {
+			return requests
+		}
+
+		clusterStores := &smv1alpha1.ClusterSecretStoreList{}
+		if err := reader.List(ctx, clusterStores, client.MatchingFields{index.StoreSecretRefKey: index.SecretRefValue(ref)}); err != nil {
+			r.Log.Error(err, "unable to list ClusterSecretStores referencing secret", "secret", ref)
+			return requests
+		}
+		for _, store := range clusterStores.Items {
+			requests = append(requests, r.externalSecretRequests(reader,
+				client.MatchingFields{storeRefKey: storeRefIndexValue(smv1alpha1.ClusterSecretStoreKind, store.Name)})...)
+		}
+
+		clusterStores = &smv1alpha1.ClusterSecretStoreList{}
+		unscopedRef := types.NamespacedName{Name: ref.Name}
+		if err := reader.List(ctx, clusterStores, client.MatchingFields{index.StoreSecretRefKey: index.SecretRefValue(unscopedRef)}); err != nil {
+			r.Log.Error(err, "unable to list ClusterSecretStores referencing secret", "secret", ref)
+			return requests
+		}
+		for _, store := range clusterStores.Items {
+			requests = append(requests, r.externalSecretRequests(reader,
+				client.InNamespace(ref.Namespace),
+				client.MatchingFields{storeRefKey: storeRefIndexValue(smv1alpha1.ClusterSecretStoreKind, store.Name)})...)
+		}
+		return requests
+	}
+}
+
+func (r *ExternalSecretReconciler) externalSecretRequests(reader client.Reader, opts ...client.ListOption) []reconcile.Request {
+	extSecrets := &smv1alpha1.ExternalSecretList{}
+	if err := reader.List(context.Background(), extSecrets, opts...); err != nil {
+		r.Log.Error(err, "unable to list ExternalSecrets")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(extSecrets.Items))
+	for _, extSecret := range extSecrets.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: extSecret.Namespace,
+				Name:      extSecret.Name,
+			},
+		})
+	}
+	return requests
+}
+
+// storeRefKind returns the kind of the referenced store, defaulting to SecretStore.
+func storeRefKind(ref smv1alpha1.ObjectReference) string {
+	if ref.Kind == smv1alpha1.ClusterSecretStoreKind {
+		return smv1alpha1.ClusterSecretStoreKind
+	}
+	return smv1alpha1.SecretStoreKind
+}
+
+func storeRefIndexValue(kind, name string) string {
+	return kind + "/" + name
+}
+
+// templateRefs returns the index values of all ConfigMaps and Secrets
+// referenced in the templateFrom field of the ExternalSecret.
+func templateRefs(extSecret *smv1alpha1.ExternalSecret) []string {
+	var refs []string
+	for _, from := range extSecret.Spec.TemplateFrom {
+		if from.ConfigMap != nil {
+			refs = append(refs, templateRefIndexValue(configMapKind, from.ConfigMap.Name))
+		}
+		if from.Secret != nil {
+			refs = append(refs, templateRefIndexValue(secretKind, from.Secret.Name))
+		}
+	}
+	return refs
+}
+
+func templateRefIndexValue(kind, name string) string {
+	return kind + "/" + name
+}
@@ -0,0 +1,96 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package index provides the field indexes shared by the controllers. They
+// are registered once with the manager, before the controllers using them are
+// set up.
+package index
+
+import (
+	"context"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// StoreSecretRefKey indexes stores by the namespace and name of the
+// Kubernetes Secrets referenced in their spec. Secrets referenced by a
+// ClusterSecretStore without a namespace are indexed with an empty namespace,
+// as they are resolved in the namespace of the ExternalSecret.
+const StoreSecretRefKey = ".spec.secretRefs"
+
+// SetupStoreSecretRefs registers the StoreSecretRefKey index for SecretStores
+// and, unless the manager is limited to a namespace, ClusterSecretStores.
+func SetupStoreSecretRefs(indexer client.FieldIndexer, namespace string) error {
+	stores := []smv1alpha1.GenericStore{&smv1alpha1.SecretStore{}}
+	if namespace == "" {
+		stores = append(stores, &smv1alpha1.ClusterSecretStore{})
+	}
+	for _, store := range stores {
+		if err := indexer.IndexField(context.Background(), store, StoreSecretRefKey, func(rawObj runtime.Object) []string {
+			return StoreSecretRefs(rawObj.(smv1alpha1.GenericStore))
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SecretRefValue returns the StoreSecretRefKey index value of a Secret. Use an
+// empty namespace to find the ClusterSecretStores referencing the Secret
+// without a namespace.
+func SecretRefValue(ref types.NamespacedName) string {
+	return ref.String()
+}
+
+// StoreSecretRefs returns the index values of all Secrets referenced by the
+// store spec.
+func StoreSecretRefs(store smv1alpha1.GenericStore) []string {
+	_, clusterScoped := store.(*smv1alpha1.ClusterSecretStore)
+	var refs []string
+	for _, selector := range secretKeySelectors(store.GetSpec()) {
+		ref := types.NamespacedName{
+			Namespace: store.GetNamespace(),
+			Name:      selector.Name,
+		}
+		if clusterScoped {
+			ref.Namespace = smmeta.StringValue(selector.Namespace)
+		}
+		refs = append(refs, SecretRefValue(ref))
+	}
+	return refs
+}
+
+// secretKeySelectors returns the SecretKeySelectors of the backend and
+// authentication method configured in the store spec. Fields referencing
+// Secrets added to the store types must be added here as well, so that
+// changes of the Secrets are picked up.
+func secretKeySelectors(spec *smv1alpha1.SecretStoreSpec) []smmeta.SecretKeySelector {
+	var selectors []smmeta.SecretKeySelector
+	if spec.Vault != nil {
+		selectors = append(selectors, spec.Vault.Auth.SecretKeySelectors()...)
+	}
+	if spec.AWS != nil {
+		selectors = append(selectors, spec.AWS.AuthSecretRef.SecretKeySelectors()...)
+	}
+	if spec.GCP != nil && spec.GCP.AuthSecretRef != nil && spec.GCP.AuthSecretRef.JSON != nil {
+		selectors = append(selectors, *spec.GCP.AuthSecretRef.JSON)
+	}
+	return selectors
+}
@@ -0,0 +1,89 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("StoreSecretRefs", func() {
+	secretRef := func(name string) *smmeta.SecretKeySelector {
+		return &smmeta.SecretKeySelector{
+			LocalObjectReference: smmeta.LocalObjectReference{Name: name},
+			Key:                  "key",
+		}
+	}
+
+	DescribeTable("should return the Secrets referenced by a SecretStore",
+		func(spec smv1alpha1.SecretStoreSpec, expected ...string) {
+			store := &smv1alpha1.SecretStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: "team-a"},
+				Spec:       spec,
+			}
+			Expect(StoreSecretRefs(store)).To(ConsistOf(expected))
+		},
+		Entry("vault token", smv1alpha1.SecretStoreSpec{
+			Vault: &smv1alpha1.VaultStore{Auth: smv1alpha1.VaultAuth{TokenSecretRef: secretRef("token")}},
+		}, "team-a/token"),
+		Entry("vault appRole", smv1alpha1.SecretStoreSpec{
+			Vault: &smv1alpha1.VaultStore{Auth: smv1alpha1.VaultAuth{AppRole: &smv1alpha1.VaultAppRole{SecretRef: *secretRef("approle")}}},
+		}, "team-a/approle"),
+		Entry("vault kubernetes without secretRef", smv1alpha1.SecretStoreSpec{
+			Vault: &smv1alpha1.VaultStore{Auth: smv1alpha1.VaultAuth{Kubernetes: &smv1alpha1.VaultKubernetesAuth{}}},
+		}),
+		Entry("vault cert", smv1alpha1.SecretStoreSpec{
+			Vault: &smv1alpha1.VaultStore{Auth: smv1alpha1.VaultAuth{Cert: &smv1alpha1.VaultCertAuth{
+				ClientCert: *secretRef("tls"),
+				SecretRef:  *secretRef("tls-key"),
+			}}},
+		}, "team-a/tls", "team-a/tls-key"),
+		Entry("vault iam", smv1alpha1.SecretStoreSpec{
+			Vault: &smv1alpha1.VaultStore{Auth: smv1alpha1.VaultAuth{IAM: &smv1alpha1.VaultIAMAuth{
+				AuthSecretRef: &smv1alpha1.AWSAuth{AccessKeyID: secretRef("aws"), SecretAccessKey: secretRef("aws")},
+			}}},
+		}, "team-a/aws", "team-a/aws"),
+		Entry("aws", smv1alpha1.SecretStoreSpec{
+			AWS: &smv1alpha1.AWSStore{AuthSecretRef: &smv1alpha1.AWSAuth{
+				AccessKeyID:     secretRef("aws-id"),
+				SecretAccessKey: secretRef("aws-secret"),
+			}},
+		}, "team-a/aws-id", "team-a/aws-secret"),
+		Entry("gcp", smv1alpha1.SecretStoreSpec{
+			GCP: &smv1alpha1.GCPStore{AuthSecretRef: &smv1alpha1.GCPAuth{JSON: secretRef("gcp")}},
+		}, "team-a/gcp"),
+	)
+
+	It("should index Secrets referenced by a ClusterSecretStore without namespace with an empty namespace", func() {
+		scoped := secretRef("scoped")
+		scoped.Namespace = smmeta.String("team-b")
+		store := &smv1alpha1.ClusterSecretStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "store"},
+			Spec: smv1alpha1.SecretStoreSpec{
+				AWS: &smv1alpha1.AWSStore{AuthSecretRef: &smv1alpha1.AWSAuth{
+					AccessKeyID:     scoped,
+					SecretAccessKey: secretRef("unscoped"),
+				}},
+			},
+		}
+		Expect(StoreSecretRefs(store)).To(ConsistOf("team-b/scoped", "/unscoped"))
+	})
+})
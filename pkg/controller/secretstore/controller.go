@@ -0,0 +1,238 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/controller/index"
+	ctxlog "github.com/itscontained/secret-manager/pkg/log"
+	"github.com/itscontained/secret-manager/pkg/store"
+	_ "github.com/itscontained/secret-manager/pkg/store/register" // register known store backends
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
+	storeschema "github.com/itscontained/secret-manager/pkg/store/schema"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	errStoreSetupFailed = "cannot setup store client"
+	errStoreCheckFailed = "store check failed"
+
+	reasonStoreAuthFailed = "StoreAuthFailed"
+	reasonStoreReady      = "Ready"
+)
+
+// errStoreNotCheckable is returned when validating a ClusterSecretStore whose
+// credentials are referenced without namespace, as they are resolved in the
+// namespace of each ExternalSecret.
+var errStoreNotCheckable = errors.New("store credentials are resolved in the namespace of each ExternalSecret and cannot be checked")
+
+// SecretStoreReconciler reconciles a SecretStore or ClusterSecretStore object
+// by validating that the configured backend can be set up and reached.
+type SecretStoreReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+
+	// Kind is the kind of store reconciled, either SecretStore or
+	// ClusterSecretStore.
+	Kind string
+
+	// CheckInterval is the interval after which a store is validated again.
+	// A zero value disables periodic validation.
+	CheckInterval time.Duration
+}
+
+func (r *SecretStoreReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues(r.Kind, req.NamespacedName)
+	ctx = ctxlog.IntoContext(ctx, log)
+
+	secretStore := r.newStore()
+	if err := r.Get(ctx, req.NamespacedName, secretStore); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.releaseStore(ctx, req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "unable to get store")
+		return ctrl.Result{}, err
+	}
+
+	wasReady := secretStore.GetStatus().GetCondition(smmeta.TypeReady).Status == corev1.ConditionTrue
+	condition := smmeta.Available()
+	switch err := r.checkStore(ctx, secretStore); {
+	case errors.Is(err, errStoreNotCheckable):
+		log.V(1).Info("skipping validation of store", "reason", err.Error())
+		condition = condition.WithMessage(err.Error())
+	case err != nil:
+		log.Error(err, "error while validating store")
+		r.Recorder.Event(secretStore, corev1.EventTypeWarning, reasonStoreAuthFailed, smmeta.Capitalize(err.Error()))
+		secretStore.GetStatus().SetConditions(smmeta.Unavailable().WithMessage(err.Error()))
+		if uerr := r.Status().Update(ctx, secretStore); uerr != nil {
+			log.Error(uerr, "unable to update store status")
+		}
+		return ctrl.Result{}, err
+	default:
+		log.V(1).Info("successfully validated store")
+	}
+
+	if !wasReady {
+		r.Recorder.Event(secretStore, corev1.EventTypeNormal, reasonStoreReady, "Store is ready")
+	}
+	secretStore.GetStatus().SetConditions(condition)
+	if err := r.Status().Update(ctx, secretStore); err != nil {
+		log.Error(err, "unable to update store status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.CheckInterval}, nil
+}
+
+// SetupWithManager sets up the controller with the manager. The
+// index.StoreSecretRefKey index must be registered with the manager, as
+// stores are validated again when a Secret they reference changes.
+func (r *SecretStoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("secret-manager")
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.newStore(), builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: r.storesForSecret(mgr.GetClient()),
+		}).
+		Complete(r)
+}
+
+// storesForSecret maps a Kubernetes Secret to the stores referencing it, e.g.
+// for authentication.
+func (r *SecretStoreReconciler) storesForSecret(reader client.Reader) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		ref := types.NamespacedName{
+			Namespace: obj.Meta.GetNamespace(),
+			Name:      obj.Meta.GetName(),
+		}
+		if r.Kind != smv1alpha1.ClusterSecretStoreKind {
+			return r.storeRequests(reader, &smv1alpha1.SecretStoreList{},
+				client.InNamespace(ref.Namespace),
+				client.MatchingFields{index.StoreSecretRefKey: index.SecretRefValue(ref)})
+		}
+		// Secrets referenced without a namespace are resolved in the namespace
+		// of the ExternalSecret, so any Secret with the name may affect the store
+		unscopedRef := types.NamespacedName{Name: ref.Name}
+		return append(
+			r.storeRequests(reader, &smv1alpha1.ClusterSecretStoreList{},
+				client.MatchingFields{index.StoreSecretRefKey: index.SecretRefValue(ref)}),
+			r.storeRequests(reader, &smv1alpha1.ClusterSecretStoreList{},
+				client.MatchingFields{index.StoreSecretRefKey: index.SecretRefValue(unscopedRef)})...)
+	}
+}
+
+func (r *SecretStoreReconciler) storeRequests(reader client.Reader, list runtime.Object, opts ...client.ListOption) []reconcile.Request {
+	if err := reader.List(context.Background(), list, opts...); err != nil {
+		r.Log.Error(err, "unable to list stores referencing secret")
+		return nil
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		r.Log.Error(err, "unable to list stores referencing secret")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(items))
+	for _, item := range items {
+		store := item.(smv1alpha1.GenericStore)
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: store.GetNamespace(),
+				Name:      store.GetName(),
+			},
+		})
+	}
+	return requests
+}
+
+func (r *SecretStoreReconciler) newStore() smv1alpha1.GenericStore {
+	if r.Kind == smv1alpha1.ClusterSecretStoreKind {
+		return &smv1alpha1.ClusterSecretStore{}
+	}
+	return &smv1alpha1.SecretStore{}
+}
+
+// releaseStore releases the resources held by the store backends for a
+// deleted store, e.g. cached credentials.
+func (r *SecretStoreReconciler) releaseStore(ctx context.Context, name types.NamespacedName) {
+	log := ctxlog.FromContext(ctx)
+	for _, storeClient := range storeschema.GetStores() {
+		releaser, ok := storeClient.(store.Releaser)
+		if !ok {
+			continue
+		}
+		if err := releaser.Release(ctx, r.Kind, name); err != nil {
+			log.Error(err, "unable to release store resources")
+		}
+	}
+}
+
+// checkStore instantiates the store backend and, if supported by the backend,
+// verifies that it is reachable with the configured credentials.
+func (r *SecretStoreReconciler) checkStore(ctx context.Context, secretStore smv1alpha1.GenericStore) error {
+	storeClient, err := storeschema.GetStore(secretStore)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSetupFailed, err)
+	}
+
+	// a ClusterSecretStore is not used from a namespace here, so references
+	// without namespace cannot be resolved
+	storeClient, err = storeClient.New(ctx, secretStore, r.Client, secretStore.GetNamespace())
+	if errors.Is(err, resolver.ErrNamespaceRequired) {
+		return errStoreNotCheckable
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", errStoreSetupFailed, err)
+	}
+
+	if checker, ok := storeClient.(store.Checker); ok {
+		err := checker.Check(ctx)
+		if errors.Is(err, resolver.ErrNamespaceRequired) {
+			return errStoreNotCheckable
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", errStoreCheckFailed, err)
+		}
+	}
+	return nil
+}
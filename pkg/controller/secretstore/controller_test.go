@@ -0,0 +1,224 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	storeint "github.com/itscontained/secret-manager/pkg/store"
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("SecretStore Controller", func() {
+
+	const timeout = time.Second * 10
+	const interval = time.Second * 1
+
+	Context("SecretStore", func() {
+		It("A SecretStore with invalid credentials should be NotReady", func() {
+			store := sampleStore.DeepCopy()
+			store.Name = "invalid-credentials"
+			key := types.NamespacedName{
+				Name:      store.Name,
+				Namespace: store.Namespace,
+			}
+
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return nil, fmt.Errorf("artificial test error")
+			})
+
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			fetched := &smv1alpha1.SecretStore{}
+			Eventually(func() bool {
+				By("Fetching the SecretStore successfully")
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				By("Checking the status condition")
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Unavailable()) &&
+					matches(fetchedCond.Message, errStoreSetupFailed) &&
+					matches(fetchedCond.Message, "artificial test error")
+			}, timeout, interval).Should(BeTrue(), "The SecretStore should have a NotReady condition")
+		})
+
+		It("A SecretStore with valid credentials should be Ready", func() {
+			store := sampleStore.DeepCopy()
+			store.Name = "valid-credentials"
+			key := types.NamespacedName{
+				Name:      store.Name,
+				Namespace: store.Namespace,
+			}
+
+			storeFactory.WithNew(func(context.Context, smv1alpha1.GenericStore,
+				client.Client, string) (storeint.Client, error) {
+				return storeFactory, nil
+			})
+
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			fetched := &smv1alpha1.SecretStore{}
+			Eventually(func() bool {
+				By("Fetching the SecretStore successfully")
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				By("Checking the status condition")
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Available())
+			}, timeout, interval).Should(BeTrue(), "The SecretStore should have a Ready condition")
+		})
+
+		It("A SecretStore should be validated again when its credentials Secret changes", func() {
+			credentials := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "vault-token",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{"token": []byte("expired")},
+			}
+			store := sampleStore.DeepCopy()
+			store.Name = "rotated-credentials"
+			store.Spec.Vault.Auth.TokenSecretRef = &smmeta.SecretKeySelector{
+				LocalObjectReference: smmeta.LocalObjectReference{Name: credentials.Name},
+				Key:                  "token",
+			}
+			key := types.NamespacedName{
+				Name:      store.Name,
+				Namespace: store.Namespace,
+			}
+
+			storeFactory.WithNew(func(ctx context.Context, _ smv1alpha1.GenericStore,
+				kube client.Client, namespace string) (storeint.Client, error) {
+				secret := &corev1.Secret{}
+				if err := kube.Get(ctx, types.NamespacedName{Namespace: namespace, Name: credentials.Name}, secret); err != nil {
+					return nil, err
+				}
+				if string(secret.Data["token"]) != "valid" {
+					return nil, fmt.Errorf("permission denied")
+				}
+				return storeFactory, nil
+			})
+
+			By("Creating the credentials Secret successfully")
+			Expect(k8sClient.Create(context.Background(), credentials)).Should(Succeed())
+			defer func() {
+				By("Deleting the credentials Secret successfully")
+				Expect(k8sClient.Delete(context.Background(), credentials)).Should(Succeed())
+			}()
+
+			By("Creating the SecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the SecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			fetched := &smv1alpha1.SecretStore{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Unavailable()) &&
+					matches(fetchedCond.Message, "permission denied")
+			}, timeout, interval).Should(BeTrue(), "The SecretStore should have a NotReady condition")
+
+			By("Rotating the credentials Secret")
+			credentials.Data["token"] = []byte("valid")
+			Expect(k8sClient.Update(context.Background(), credentials)).Should(Succeed())
+
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				return fetched.Status.GetCondition(smmeta.TypeReady).Matches(smmeta.Available())
+			}, timeout, interval).Should(BeTrue(), "The SecretStore should have a Ready condition")
+		})
+	})
+
+	Context("ClusterSecretStore", func() {
+		It("A ClusterSecretStore with credentials without namespace should be Ready without check", func() {
+			store := &smv1alpha1.ClusterSecretStore{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "namespaceless-credentials",
+				},
+				Spec: *sampleStore.Spec.DeepCopy(),
+			}
+			store.Spec.Vault.Auth.TokenSecretRef = &smmeta.SecretKeySelector{
+				LocalObjectReference: smmeta.LocalObjectReference{Name: "vault-token"},
+				Key:                  "token",
+			}
+			key := types.NamespacedName{
+				Name: store.Name,
+			}
+
+			storeFactory.WithNew(func(ctx context.Context, store smv1alpha1.GenericStore,
+				kube client.Client, namespace string) (storeint.Client, error) {
+				_, err := resolver.New(kube, store, namespace).SecretKey(ctx, *store.GetSpec().Vault.Auth.TokenSecretRef)
+				if err != nil {
+					return nil, err
+				}
+				return storeFactory, nil
+			})
+
+			By("Creating the ClusterSecretStore successfully")
+			Expect(k8sClient.Create(context.Background(), store)).Should(Succeed())
+			defer func() {
+				By("Deleting the ClusterSecretStore successfully")
+				Expect(k8sClient.Delete(context.Background(), store)).Should(Succeed())
+			}()
+
+			fetched := &smv1alpha1.ClusterSecretStore{}
+			Eventually(func() bool {
+				Expect(k8sClient.Get(context.Background(), key, fetched)).Should(Succeed())
+				fetchedCond := fetched.Status.GetCondition(smmeta.TypeReady)
+				return fetchedCond.Matches(smmeta.Available()) &&
+					matches(fetchedCond.Message, errStoreNotCheckable.Error())
+			}, timeout, interval).Should(BeTrue(), "The ClusterSecretStore should be Ready without being checked")
+		})
+	})
+})
+
+// arbitrary SecretStore to use when injecting factory
+var sampleStore = &smv1alpha1.SecretStore{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:      "vault",
+		Namespace: "default",
+	},
+	Spec: smv1alpha1.SecretStoreSpec{
+		Vault: &smv1alpha1.VaultStore{
+			Server: "http://localhost:12345",
+		},
+	},
+}
@@ -0,0 +1,95 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/controller/testenv"
+	fakestore "github.com/itscontained/secret-manager/pkg/store/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest/printer"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// These tests use Ginkgo (BDD-style Go testing framework). Refer to
+// http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
+
+var (
+	k8sClient    client.Client
+	testEnv      *testenv.Environment
+	storeFactory *fakestore.Client
+)
+
+func TestAPIs(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecsWithDefaultAndCustomReporters(t,
+		"Controller Suite",
+		[]Reporter{printer.NewlineReporter{}})
+}
+
+var _ = BeforeSuite(func(done Done) {
+	logf.SetLogger(zap.LoggerTo(GinkgoWriter, true))
+
+	By("Bootstrapping test environment")
+	var err error
+	testEnv, err = testenv.Start()
+	Expect(err).ToNot(HaveOccurred())
+	k8sClient = testEnv.Client
+	storeFactory = testEnv.StoreFactory
+
+	err = (&SecretStoreReconciler{
+		Client: k8sClient,
+		Scheme: testEnv.Manager.GetScheme(),
+		Log:    ctrl.Log.WithName("controllers").WithName("SecretStore"),
+		Kind:   smv1alpha1.SecretStoreKind,
+	}).SetupWithManager(testEnv.Manager)
+	Expect(err).ToNot(HaveOccurred())
+
+	err = (&SecretStoreReconciler{
+		Client: k8sClient,
+		Scheme: testEnv.Manager.GetScheme(),
+		Log:    ctrl.Log.WithName("controllers").WithName("ClusterSecretStore"),
+		Kind:   smv1alpha1.ClusterSecretStoreKind,
+	}).SetupWithManager(testEnv.Manager)
+	Expect(err).ToNot(HaveOccurred())
+
+	go func() {
+		defer GinkgoRecover()
+		Expect(testEnv.Manager.Start(ctrl.SetupSignalHandler())).ToNot(HaveOccurred())
+	}()
+
+	close(done)
+}, 60)
+
+var _ = AfterSuite(func() {
+	By("Tearing down the test environment")
+	if testEnv != nil {
+		Expect(testEnv.Stop()).To(Succeed())
+	}
+})
+
+func matches(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
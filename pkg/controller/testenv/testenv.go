@@ -0,0 +1,93 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testenv bootstraps the local control plane shared by the
+// controller test suites.
+package testenv
+
+import (
+	"path/filepath"
+	"runtime"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/controller/index"
+	fakestore "github.com/itscontained/secret-manager/pkg/store/fake"
+
+	"k8s.io/client-go/kubernetes/scheme"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// Environment is a local control plane with the secret-manager CRDs
+// installed and a manager for it, which has not been started yet.
+type Environment struct {
+	envtest.Environment
+
+	Manager ctrl.Manager
+	// Client reads directly from the API server instead of the cache of
+	// the manager.
+	Client client.Client
+	// StoreFactory is registered as backend of Vault stores.
+	StoreFactory *fakestore.Client
+}
+
+// Start starts the control plane and sets up the manager with the field
+// indexes shared by the controllers.
+func Start() (*Environment, error) {
+	// resolve the CRDs relative to this file, independent of the test package
+	_, file, _, _ := runtime.Caller(0)
+	env := &Environment{
+		Environment: envtest.Environment{
+			CRDDirectoryPaths: []string{filepath.Join(filepath.Dir(file), "..", "..", "..", "deploy", "crds")},
+		},
+	}
+
+	cfg, err := env.Environment.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := smv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		return nil, err
+	}
+	if err := smmeta.AddToScheme(scheme.Scheme); err != nil {
+		return nil, err
+	}
+
+	env.Manager, err = ctrl.NewManager(cfg, ctrl.Options{
+		Scheme: scheme.Scheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := index.SetupStoreSecretRefs(env.Manager.GetFieldIndexer(), ""); err != nil {
+		return nil, err
+	}
+
+	// do not use mgr.GetClient()
+	// see https://github.com/kubernetes-sigs/controller-runtime/issues/343#issuecomment-469435686
+	env.Client, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		return nil, err
+	}
+
+	env.StoreFactory = fakestore.New()
+	env.StoreFactory.RegisterAs(&smv1alpha1.SecretStoreSpec{
+		Vault: &smv1alpha1.VaultStore{},
+	})
+	return env, nil
+}
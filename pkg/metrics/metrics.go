@@ -0,0 +1,204 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	namespace = "secret_manager"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+var (
+	// SyncCalls counts the reconciles of ExternalSecrets by store kind, store
+	// backend and outcome.
+	SyncCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "externalsecret",
+		Name:      "sync_calls_total",
+		Help:      "Total number of ExternalSecret syncs by store kind, backend and result.",
+	}, []string{"store_kind", "backend", "result"})
+
+	// StoreRequestDuration observes the latency of requests to store backends.
+	StoreRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "store",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of requests to the store backends by backend, operation and result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"backend", "operation", "result"})
+
+	// ExternalSecrets tracks the Ready status and last successful sync time
+	// of every known ExternalSecret.
+	ExternalSecrets = newExternalSecretCollector()
+)
+
+func init() {
+	metrics.Registry.MustRegister(SyncCalls, StoreRequestDuration, ExternalSecrets)
+}
+
+// Result returns the result label value for an error.
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
+
+type externalSecretState struct {
+	ready    bool
+	lastSync time.Time
+}
+
+// ExternalSecretCollector is a prometheus.Collector which exposes the number of
+// ExternalSecrets by Ready status and the time elapsed since the last successful
+// sync of each ExternalSecret.
+type ExternalSecretCollector struct {
+	mu      sync.Mutex
+	secrets map[types.NamespacedName]externalSecretState
+	now     func() time.Time
+
+	readyDesc   *prometheus.Desc
+	syncAgeDesc *prometheus.Desc
+}
+
+var _ prometheus.Collector = &ExternalSecretCollector{}
+
+func newExternalSecretCollector() *ExternalSecretCollector {
+	return &ExternalSecretCollector{
+		secrets: make(map[types.NamespacedName]externalSecretState),
+		now:     time.Now,
+		readyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "externalsecret", "ready"),
+			"Number of ExternalSecrets by namespace and Ready status.",
+			[]string{"namespace", "status"}, nil),
+		syncAgeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "externalsecret", "seconds_since_last_sync"),
+			"Seconds since the last successful sync of an ExternalSecret.",
+			[]string{"namespace", "name"}, nil),
+	}
+}
+
+// SetSynced records a successful sync of the ExternalSecret.
+func (c *ExternalSecretCollector) SetSynced(key types.NamespacedName, syncTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.secrets[key] = externalSecretState{ready: true, lastSync: syncTime}
+}
+
+// SetFailed records a failed sync of the ExternalSecret. The time of the last
+// successful sync is retained.
+func (c *ExternalSecretCollector) SetFailed(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.secrets[key]
+	state.ready = false
+	c.secrets[key] = state
+}
+
+// Delete removes the ExternalSecret from the collected metrics.
+func (c *ExternalSecretCollector) Delete(key types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.secrets, key)
+}
+
+func (c *ExternalSecretCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.readyDesc
+	ch <- c.syncAgeDesc
+}
+
+func (c *ExternalSecretCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	type readyKey struct {
+		namespace string
+		status    string
+	}
+	ready := make(map[readyKey]float64)
+	for key, state := range c.secrets {
+		status := "False"
+		if state.ready {
+			status = "True"
+		}
+		ready[readyKey{namespace: key.Namespace, status: status}]++
+		if !state.lastSync.IsZero() {
+			ch <- prometheus.MustNewConstMetric(c.syncAgeDesc, prometheus.GaugeValue,
+				now.Sub(state.lastSync).Seconds(), key.Namespace, key.Name)
+		}
+	}
+	for key, count := range ready {
+		ch <- prometheus.MustNewConstMetric(c.readyDesc, prometheus.GaugeValue, count, key.namespace, key.status)
+	}
+}
+
+// InstrumentStoreClient wraps a store client to observe the latency of its
+// requests to the store backend.
+func InstrumentStoreClient(backend string, c store.Client) store.Client {
+	return &instrumentedClient{
+		backend: backend,
+		client:  c,
+	}
+}
+
+type instrumentedClient struct {
+	backend string
+	client  store.Client
+}
+
+var _ store.Client = &instrumentedClient{}
+
+func (i *instrumentedClient) New(ctx context.Context, s smv1alpha1.GenericStore, kube client.Client, namespace string) (store.Client, error) {
+	c, err := i.client.New(ctx, s, kube, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return InstrumentStoreClient(i.backend, c), nil
+}
+
+func (i *instrumentedClient) GetSecret(ctx context.Context, ref smv1alpha1.RemoteReference) ([]byte, error) {
+	start := time.Now()
+	data, err := i.client.GetSecret(ctx, ref)
+	i.observe("GetSecret", start, err)
+	return data, err
+}
+
+func (i *instrumentedClient) GetSecretMap(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string][]byte, error) {
+	start := time.Now()
+	data, err := i.client.GetSecretMap(ctx, ref)
+	i.observe("GetSecretMap", start, err)
+	return data, err
+}
+
+func (i *instrumentedClient) observe(operation string, start time.Time, err error) {
+	StoreRequestDuration.WithLabelValues(i.backend, operation, Result(err)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,198 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store"
+	"github.com/itscontained/secret-manager/pkg/store/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Metrics", func() {
+	Context("ExternalSecretCollector", func() {
+		var (
+			collector *ExternalSecretCollector
+			now       time.Time
+		)
+
+		key := types.NamespacedName{Namespace: "team-a", Name: "db-credentials"}
+
+		expect := func(expected string) {
+			ExpectWithOffset(1, testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			now = time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+			collector = newExternalSecretCollector()
+			collector.now = func() time.Time { return now }
+		})
+
+		It("should report a successful sync", func() {
+			collector.SetSynced(key, now.Add(-90*time.Second))
+			expect(`
+# HELP secret_manager_externalsecret_ready Number of ExternalSecrets by namespace and Ready status.
+# TYPE secret_manager_externalsecret_ready gauge
+secret_manager_externalsecret_ready{namespace="team-a",status="True"} 1
+# HELP secret_manager_externalsecret_seconds_since_last_sync Seconds since the last successful sync of an ExternalSecret.
+# TYPE secret_manager_externalsecret_seconds_since_last_sync gauge
+secret_manager_externalsecret_seconds_since_last_sync{name="db-credentials",namespace="team-a"} 90
+`)
+		})
+
+		It("should keep the last successful sync time after a failed sync", func() {
+			collector.SetSynced(key, now.Add(-90*time.Second))
+			collector.SetFailed(key)
+			collector.SetFailed(types.NamespacedName{Namespace: "team-a", Name: "never-synced"})
+			expect(`
+# HELP secret_manager_externalsecret_ready Number of ExternalSecrets by namespace and Ready status.
+# TYPE secret_manager_externalsecret_ready gauge
+secret_manager_externalsecret_ready{namespace="team-a",status="False"} 2
+# HELP secret_manager_externalsecret_seconds_since_last_sync Seconds since the last successful sync of an ExternalSecret.
+# TYPE secret_manager_externalsecret_seconds_since_last_sync gauge
+secret_manager_externalsecret_seconds_since_last_sync{name="db-credentials",namespace="team-a"} 90
+`)
+		})
+
+		It("should remove the series of a deleted ExternalSecret", func() {
+			other := types.NamespacedName{Namespace: "team-b", Name: "api-key"}
+			collector.SetSynced(key, now.Add(-90*time.Second))
+			collector.SetSynced(other, now.Add(-30*time.Second))
+			collector.Delete(key)
+			expect(`
+# HELP secret_manager_externalsecret_ready Number of ExternalSecrets by namespace and Ready status.
+# TYPE secret_manager_externalsecret_ready gauge
+secret_manager_externalsecret_ready{namespace="team-b",status="True"} 1
+# HELP secret_manager_externalsecret_seconds_since_last_sync Seconds since the last successful sync of an ExternalSecret.
+# TYPE secret_manager_externalsecret_seconds_since_last_sync gauge
+secret_manager_externalsecret_seconds_since_last_sync{name="api-key",namespace="team-b"} 30
+`)
+
+			collector.Delete(other)
+			expect("")
+		})
+	})
+
+	Context("SyncCalls", func() {
+		BeforeEach(func() {
+			SyncCalls.Reset()
+		})
+
+		It("should count syncs by store kind, backend and result", func() {
+			SyncCalls.WithLabelValues(smv1alpha1.SecretStoreKind, "vault", Result(nil)).Inc()
+			SyncCalls.WithLabelValues(smv1alpha1.SecretStoreKind, "vault", Result(errors.New("sync failed"))).Inc()
+			SyncCalls.WithLabelValues(smv1alpha1.SecretStoreKind, "vault", Result(errors.New("sync failed"))).Inc()
+			SyncCalls.WithLabelValues(smv1alpha1.ClusterSecretStoreKind, "aws", Result(nil)).Inc()
+
+			Expect(testutil.ToFloat64(SyncCalls.WithLabelValues(smv1alpha1.SecretStoreKind, "vault", ResultSuccess))).To(Equal(1.0))
+			Expect(testutil.ToFloat64(SyncCalls.WithLabelValues(smv1alpha1.SecretStoreKind, "vault", ResultError))).To(Equal(2.0))
+			Expect(testutil.ToFloat64(SyncCalls.WithLabelValues(smv1alpha1.ClusterSecretStoreKind, "aws", ResultSuccess))).To(Equal(1.0))
+			Expect(collectAndCount(SyncCalls)).To(Equal(3))
+		})
+	})
+
+	Context("InstrumentStoreClient", func() {
+		var (
+			ctx     context.Context
+			backend *fake.Client
+			client  store.Client
+		)
+
+		sampleCount := func(operation, result string) uint64 {
+			metric := &dto.Metric{}
+			ExpectWithOffset(1, StoreRequestDuration.WithLabelValues("vault", operation, result).(prometheus.Metric).Write(metric)).To(Succeed())
+			return metric.GetHistogram().GetSampleCount()
+		}
+
+		BeforeEach(func() {
+			StoreRequestDuration.Reset()
+			ctx = context.Background()
+			backend = fake.New()
+			client = InstrumentStoreClient("vault", backend)
+		})
+
+		It("should observe successful requests", func() {
+			backend.WithGetSecret([]byte("s3cr3t"), nil)
+			backend.WithGetSecretMap(map[string][]byte{"password": []byte("s3cr3t")}, nil)
+
+			value, err := client.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal([]byte("s3cr3t")))
+			values, err := client.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "db"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(values).To(Equal(map[string][]byte{"password": []byte("s3cr3t")}))
+
+			Expect(sampleCount("GetSecret", ResultSuccess)).To(Equal(uint64(1)))
+			Expect(sampleCount("GetSecretMap", ResultSuccess)).To(Equal(uint64(1)))
+			Expect(collectAndCount(StoreRequestDuration)).To(Equal(2))
+		})
+
+		It("should observe failed requests", func() {
+			backend.WithGetSecret(nil, errors.New("permission denied"))
+
+			_, err := client.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db"})
+			Expect(err).To(MatchError("permission denied"))
+			_, err = client.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db"})
+			Expect(err).To(MatchError("permission denied"))
+
+			Expect(sampleCount("GetSecret", ResultError)).To(Equal(uint64(2)))
+			Expect(collectAndCount(StoreRequestDuration)).To(Equal(1))
+		})
+
+		It("should instrument the clients it creates", func() {
+			backend.WithNew(func(context.Context, smv1alpha1.GenericStore, ctrlclient.Client, string) (store.Client, error) {
+				return backend, nil
+			})
+			backend.WithGetSecret(nil, errors.New("permission denied"))
+
+			created, err := client.New(ctx, &smv1alpha1.SecretStore{}, nil, "default")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = created.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db"})
+			Expect(err).To(HaveOccurred())
+
+			Expect(sampleCount("GetSecret", ResultError)).To(Equal(uint64(1)))
+			Expect(collectAndCount(StoreRequestDuration)).To(Equal(1))
+		})
+	})
+})
+
+// collectAndCount returns the number of metrics collected from the collector.
+func collectAndCount(c prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	count := 0
+	for range ch {
+		count++
+	}
+	return count
+}
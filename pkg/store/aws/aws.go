@@ -15,6 +15,7 @@ limitations under the License.
 package aws
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -24,19 +25,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
 	"github.com/aws/aws-sdk-go-v2/aws/external"
-	"github.com/aws/aws-sdk-go-v2/aws/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/go-logr/logr"
 
-	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
 	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
 	ctxlog "github.com/itscontained/secret-manager/pkg/log"
 	"github.com/itscontained/secret-manager/pkg/store"
+	"github.com/itscontained/secret-manager/pkg/store/awsauth"
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
 	"github.com/itscontained/secret-manager/pkg/store/schema"
-
-	corev1 "k8s.io/api/core/v1"
+	"github.com/itscontained/secret-manager/pkg/util/property"
 
 	"k8s.io/apimachinery/pkg/types"
 
@@ -44,18 +45,32 @@ import (
 )
 
 var _ store.Client = &AWS{}
+var _ store.Checker = &AWS{}
+var _ store.Releaser = &AWS{}
 
 const (
 	AWSSecretsmanagerEndpoint = "AWS_SECRETSMANAGER_ENDPOINT"
-	AWSSTSEndpoint            = "AWS_STS_ENDPOINT"
+	AWSSTSEndpoint            = awsauth.STSEndpoint
+	AWSSSMEndpoint            = "AWS_SSM_ENDPOINT"
+
+	// VersionIDPrefix marks a version of a remote reference as VersionId, all
+	// other versions are used as VersionStage, e.g. "AWSPREVIOUS".
+	VersionIDPrefix = "uuid/"
 )
 
+// SecretsManagerClient is the subset of the Secrets Manager API used by the
+// store.
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error)
+}
+
 type AWS struct {
-	kube      ctrlclient.Client
-	store     smv1alpha1.GenericStore
-	log       logr.Logger
-	client    *secretsmanager.Client
-	namespace string
+	store    smv1alpha1.GenericStore
+	resolver *resolver.Resolver
+	log      logr.Logger
+	client   SecretsManagerClient
+	ssm      ParameterStoreClient
+	sts      *sts.Client
 }
 
 func init() {
@@ -67,10 +82,9 @@ func init() {
 func (a *AWS) New(ctx context.Context, store smv1alpha1.GenericStore, kube ctrlclient.Client, namespace string) (store.Client, error) {
 	log := ctxlog.FromContext(ctx)
 	awsClient := &AWS{
-		kube:      kube,
-		store:     store,
-		log:       log,
-		namespace: namespace,
+		store:    store,
+		resolver: resolver.New(kube, store, namespace),
+		log:      log,
 	}
 
 	cfg, err := awsClient.newConfig(ctx)
@@ -78,28 +92,50 @@ func (a *AWS) New(ctx context.Context, store smv1alpha1.GenericStore, kube ctrlc
 		return nil, err
 	}
 
-	awsClient.client = secretsmanager.New(*cfg)
+	switch service := store.GetSpec().AWS.Service; service {
+	case "", smv1alpha1.AWSServiceSecretsManager:
+		awsClient.client = &secretsManagerClient{client: secretsmanager.New(*cfg)}
+	case smv1alpha1.AWSServiceParameterStore:
+		awsClient.ssm = &parameterStoreClient{client: ssm.New(*cfg)}
+	default:
+		return nil, fmt.Errorf("unsupported AWS service %q", service)
+	}
+	awsClient.sts = sts.New(*cfg)
 	return awsClient, nil
 }
 
+// Check verifies the AWS credentials by requesting the caller identity.
+func (a *AWS) Check(ctx context.Context) error {
+	req := a.sts.GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	if _, err := req.Send(ctx); err != nil {
+		return fmt.Errorf("error getting caller identity: %w", err)
+	}
+	return nil
+}
+
+// Release forgets the credentials cached for the deleted store.
+func (a *AWS) Release(ctx context.Context, kind string, name types.NamespacedName) error {
+	awsauth.Release(kind, name)
+	return nil
+}
+
 func (a *AWS) GetSecret(ctx context.Context, ref smv1alpha1.RemoteReference) ([]byte, error) {
 	version := ""
 	if ref.Version != nil {
 		version = *ref.Version
 	}
-	data, err := a.readSecret(ctx, ref.Name, version)
+	value, err := a.readValue(ctx, ref.Name, version)
 	if err != nil {
 		return nil, err
 	}
-	property := ""
-	if ref.Property != nil {
-		property = *ref.Property
+	if ref.Property == nil {
+		return value, nil
 	}
-	value, exists := data[property]
-	if !exists {
-		return nil, fmt.Errorf("property %q not found in secret response", property)
+	data, err := unmarshalSecret(value)
+	if err != nil {
+		return nil, err
 	}
-	return value, nil
+	return property.Get(data, *ref.Property)
 }
 
 func (a *AWS) GetSecretMap(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string][]byte, error) {
@@ -107,29 +143,61 @@ func (a *AWS) GetSecretMap(ctx context.Context, ref smv1alpha1.RemoteReference)
 	if ref.Version != nil {
 		version = *ref.Version
 	}
-	return a.readSecret(ctx, ref.Name, version)
+	if a.ssm != nil {
+		return a.readParametersByPath(ctx, ref.Name, version)
+	}
+	value, err := a.readSecret(ctx, ref.Name, version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := unmarshalSecret(value)
+	if err != nil {
+		return nil, err
+	}
+	return property.Values(data)
+}
+
+// readValue returns the value of a secret or parameter depending on the
+// configured service.
+func (a *AWS) readValue(ctx context.Context, name, version string) ([]byte, error) {
+	if a.ssm != nil {
+		return a.readParameter(ctx, name, version)
+	}
+	return a.readSecret(ctx, name, version)
 }
 
-func (a *AWS) readSecret(ctx context.Context, id, version string) (map[string][]byte, error) {
+// readSecret returns the SecretString or, for binary secrets, the SecretBinary
+// of the secret.
+func (a *AWS) readSecret(ctx context.Context, id, version string) ([]byte, error) {
 	input := &secretsmanager.GetSecretValueInput{
 		SecretId: aws.String(id),
 	}
-	if version != "" {
+	switch {
+	case strings.HasPrefix(version, VersionIDPrefix):
+		input.VersionId = aws.String(strings.TrimPrefix(version, VersionIDPrefix))
+	case version != "":
 		input.VersionStage = aws.String(version)
 	}
-	req := a.client.GetSecretValueRequest(input)
-	resp, err := req.Send(ctx)
+	resp, err := a.client.GetSecretValue(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("error getting secret value: %w", err)
 	}
-	smData := make(map[string]string)
-	err = json.Unmarshal([]byte(*resp.SecretString), &smData)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unmarshal secret value: %w", err)
+	if resp.SecretString != nil {
+		return []byte(*resp.SecretString), nil
 	}
-	secretData := make(map[string][]byte)
-	for k, v := range smData {
-		secretData[k] = []byte(v)
+	if resp.SecretBinary != nil {
+		return resp.SecretBinary, nil
+	}
+	return nil, fmt.Errorf("secret %q has neither a string nor a binary value", id)
+}
+
+// unmarshalSecret unmarshals a secret value holding a JSON object.
+func unmarshalSecret(value []byte) (map[string]interface{}, error) {
+	secretData := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	if err := dec.Decode(&secretData); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal secret value as JSON object: %w", err)
 	}
 	return secretData, nil
 }
@@ -139,67 +207,36 @@ func (a *AWS) newConfig(ctx context.Context) (*aws.Config, error) {
 	if err != nil {
 		return nil, err
 	}
-	cfg.EndpointResolver = &EndpointResolver{}
+	cfg.EndpointResolver = &EndpointResolver{res: endpoints.NewDefaultResolver()}
 	spec := *a.store.GetSpec().AWS
 	if spec.Region != nil {
 		cfg.Region = *spec.Region
 	}
-	if spec.AuthSecretRef == nil {
-		return &cfg, nil
-	}
-	scoped := true
-	if a.store.GetTypeMeta().String() == "ClusterSecretStore" {
-		scoped = false
-	}
-	if spec.AuthSecretRef.AccessKeyID == nil || spec.AuthSecretRef.SecretAccessKey == nil {
-		return nil, fmt.Errorf("missing accessKeyID/secretAccessKey in store config")
-	}
-	aKid, err := a.secretKeyRef(ctx, a.store.GetNamespace(), *spec.AuthSecretRef.AccessKeyID, scoped)
-	if err != nil {
-		return nil, err
-	}
-	sak, err := a.secretKeyRef(ctx, a.store.GetNamespace(), *spec.AuthSecretRef.SecretAccessKey, scoped)
-	if err != nil {
-		return nil, err
-	}
-	nScp := aws.NewStaticCredentialsProvider(aKid, sak, "secret-manager")
-	cfg.Credentials = nScp
-	if spec.AuthSecretRef.Role != nil {
-		role, err := a.secretKeyRef(ctx, a.store.GetNamespace(), *spec.AuthSecretRef.Role, scoped)
-		if err != nil {
+	if spec.AuthSecretRef != nil {
+		if err := awsauth.ConfigureAuth(ctx, &cfg, a.store, spec.AuthSecretRef, a.resolver); err != nil {
 			return nil, err
 		}
-		stsClient := sts.New(cfg)
-		stsCp := stscreds.NewAssumeRoleProvider(stsClient, role)
-		cfg.Credentials = stsCp
 	}
+	awsauth.AssumeRoles(&cfg, a.store, spec.AssumeRoles())
 	return &cfg, nil
 }
 
-func (a *AWS) secretKeyRef(ctx context.Context, namespace string, secretRef smmeta.SecretKeySelector, scoped bool) (string, error) {
-	var secret corev1.Secret
-	ref := types.NamespacedName{
-		Namespace: namespace,
-		Name:      secretRef.Name,
-	}
-	if !scoped && secretRef.Namespace != nil {
-		ref.Namespace = *secretRef.Namespace
-	}
-	err := a.kube.Get(ctx, ref, &secret)
+type secretsManagerClient struct {
+	client *secretsmanager.Client
+}
+
+func (c *secretsManagerClient) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	resp, err := c.client.GetSecretValueRequest(input).Send(ctx)
 	if err != nil {
-		return "", err
-	}
-	keyBytes, ok := secret.Data[secretRef.Key]
-	if !ok {
-		return "", fmt.Errorf("no data for %q in secret '%s/%s'", secretRef.Key, secretRef.Name, namespace)
+		return nil, err
 	}
-	value := strings.TrimSpace(string(keyBytes))
-	return value, nil
+	return resp.GetSecretValueOutput, nil
 }
 
-// EndpointResolver resolves custom endpoints for aws services
+// EndpointResolver resolves custom endpoints for aws services, falling back
+// to the default endpoints of the SDK
 type EndpointResolver struct {
-	res endpoints.Resolver
+	res *endpoints.Resolver
 }
 
 // ResolveEndpoint resolves custom endpoints if provided
@@ -211,6 +248,13 @@ func (r *EndpointResolver) ResolveEndpoint(service, region string) (aws.Endpoint
 			}, nil
 		}
 	}
+	if ep := os.Getenv(AWSSSMEndpoint); ep != "" {
+		if service == "ssm" {
+			return aws.Endpoint{
+				URL: ep,
+			}, nil
+		}
+	}
 	if ep := os.Getenv(AWSSTSEndpoint); ep != "" {
 		if service == "sts" {
 			return aws.Endpoint{
@@ -218,5 +262,8 @@ func (r *EndpointResolver) ResolveEndpoint(service, region string) (aws.Endpoint
 			}, nil
 		}
 	}
+	if r.res == nil {
+		return endpoints.NewDefaultResolver().ResolveEndpoint(service, region)
+	}
 	return r.res.ResolveEndpoint(service, region)
 }
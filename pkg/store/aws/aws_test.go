@@ -0,0 +1,194 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store/aws/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AWS Secrets Manager", func() {
+	var (
+		ctx    context.Context
+		client *fake.Client
+		store  *AWS
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = fake.NewFakeClient()
+		store = &AWS{client: client}
+	})
+
+	withString := func(value string) {
+		client.WithGetSecretValue(&secretsmanager.GetSecretValueOutput{SecretString: aws.String(value)}, nil)
+	}
+
+	Context("with a JSON secret", func() {
+		BeforeEach(func() {
+			withString(`{"username":"admin","port":5432,"db":{"password":"s3cr3t"}}`)
+		})
+
+		It("should return the property", func() {
+			value, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db", Property: aws.String("db.password")})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(value)).To(Equal("s3cr3t"))
+		})
+
+		It("should return the whole secret without property", func() {
+			value, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(value)).To(Equal(`{"username":"admin","port":5432,"db":{"password":"s3cr3t"}}`))
+		})
+
+		It("should fail on a missing property", func() {
+			_, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db", Property: aws.String("password")})
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+		})
+
+		It("should return all values as map", func() {
+			values, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "db"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(values).To(Equal(map[string][]byte{
+				"username": []byte("admin"),
+				"port":     []byte("5432"),
+				"db":       []byte(`{"password":"s3cr3t"}`),
+			}))
+		})
+	})
+
+	Context("with a plain text secret", func() {
+		BeforeEach(func() {
+			withString("s3cr3t")
+		})
+
+		It("should return the value as-is without property", func() {
+			value, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(value)).To(Equal("s3cr3t"))
+		})
+
+		It("should fail with property", func() {
+			_, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "password", Property: aws.String("password")})
+			Expect(err).To(MatchError(ContainSubstring("JSON object")))
+		})
+
+		It("should fail as map", func() {
+			_, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "password"})
+			Expect(err).To(MatchError(ContainSubstring("JSON object")))
+		})
+	})
+
+	Context("with a binary secret", func() {
+		binary := []byte{0xfb, 0xff, 0x00}
+
+		It("should return the binary value", func() {
+			client.WithGetSecretValue(&secretsmanager.GetSecretValueOutput{SecretBinary: binary}, nil)
+			value, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "keystore"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(binary))
+		})
+
+		It("should support JSON in binary secrets", func() {
+			client.WithGetSecretValue(&secretsmanager.GetSecretValueOutput{SecretBinary: []byte(`{"key":"value"}`)}, nil)
+			values, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "json"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(values).To(Equal(map[string][]byte{"key": []byte("value")}))
+		})
+	})
+
+	It("should fail on a secret without value", func() {
+		client.WithGetSecretValue(&secretsmanager.GetSecretValueOutput{}, nil)
+		_, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "empty"})
+		Expect(err).To(MatchError(ContainSubstring("neither a string nor a binary value")))
+	})
+
+	It("should pass the version stage and surface API errors", func() {
+		client.GetSecretValueFn = func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+			Expect(aws.StringValue(input.SecretId)).To(Equal("db"))
+			Expect(aws.StringValue(input.VersionStage)).To(Equal("AWSPREVIOUS"))
+			Expect(input.VersionId).To(BeNil())
+			return nil, errors.New("access denied")
+		}
+		_, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "db", Version: aws.String("AWSPREVIOUS")})
+		Expect(err).To(MatchError(ContainSubstring("access denied")))
+	})
+
+	It("should pass a prefixed version as version id", func() {
+		client.GetSecretValueFn = func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+			Expect(aws.StringValue(input.VersionId)).To(Equal("c3a0b4a4-5a2b-4d6e-9f1a-0e8d5c6b7a8f"))
+			Expect(input.VersionStage).To(BeNil())
+			return &secretsmanager.GetSecretValueOutput{SecretString: aws.String("s3cr3t")}, nil
+		}
+		value, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{
+			Name:    "db",
+			Version: aws.String(VersionIDPrefix + "c3a0b4a4-5a2b-4d6e-9f1a-0e8d5c6b7a8f"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(value)).To(Equal("s3cr3t"))
+	})
+})
+
+var _ = Describe("EndpointResolver", func() {
+	var env map[string]string
+
+	BeforeEach(func() {
+		env = make(map[string]string)
+		for _, name := range []string{AWSSecretsmanagerEndpoint, AWSSSMEndpoint, AWSSTSEndpoint} {
+			if value, ok := os.LookupEnv(name); ok {
+				env[name] = value
+			}
+			Expect(os.Unsetenv(name)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		for name, value := range env {
+			Expect(os.Setenv(name, value)).To(Succeed())
+		}
+	})
+
+	It("should resolve the default endpoint without overrides", func() {
+		for _, resolver := range []*EndpointResolver{{}, {res: endpoints.NewDefaultResolver()}} {
+			endpoint, err := resolver.ResolveEndpoint("secretsmanager", "eu-west-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(endpoint.URL).To(Equal("https://secretsmanager.eu-west-1.amazonaws.com"))
+		}
+	})
+
+	It("should resolve the endpoint override of a service", func() {
+		Expect(os.Setenv(AWSSSMEndpoint, "http://localhost:4566")).To(Succeed())
+		defer os.Unsetenv(AWSSSMEndpoint)
+
+		resolver := &EndpointResolver{res: endpoints.NewDefaultResolver()}
+		endpoint, err := resolver.ResolveEndpoint("ssm", "eu-west-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endpoint.URL).To(Equal("http://localhost:4566"))
+		endpoint, err = resolver.ResolveEndpoint("secretsmanager", "eu-west-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endpoint.URL).To(Equal("https://secretsmanager.eu-west-1.amazonaws.com"))
+	})
+})
@@ -0,0 +1,77 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+type Client struct {
+	GetSecretValueFn func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+func NewFakeClient() *Client {
+	return &Client{
+		GetSecretValueFn: func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+			return nil, errors.New("unexpected GetSecretValue call")
+		},
+	}
+}
+
+func (c *Client) WithGetSecretValue(out *secretsmanager.GetSecretValueOutput, err error) *Client {
+	c.GetSecretValueFn = func(input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+		return out, err
+	}
+	return c
+}
+
+func (c *Client) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error) {
+	return c.GetSecretValueFn(input)
+}
+
+type ParameterStoreClient struct {
+	GetParameterFn        func(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error)
+	GetParametersByPathFn func(input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error)
+}
+
+func NewFakeParameterStoreClient() *ParameterStoreClient {
+	return &ParameterStoreClient{
+		GetParameterFn: func(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+			return nil, errors.New("unexpected GetParameter call")
+		},
+		GetParametersByPathFn: func(input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+			return nil, errors.New("unexpected GetParametersByPath call")
+		},
+	}
+}
+
+func (c *ParameterStoreClient) WithGetParameter(out *ssm.GetParameterOutput, err error) *ParameterStoreClient {
+	c.GetParameterFn = func(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+		return out, err
+	}
+	return c
+}
+
+func (c *ParameterStoreClient) GetParameter(ctx context.Context, input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	return c.GetParameterFn(input)
+}
+
+func (c *ParameterStoreClient) GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	return c.GetParametersByPathFn(input)
+}
@@ -0,0 +1,116 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// ParameterStoreClient is the subset of the Systems Manager API used by the
+// store.
+type ParameterStoreClient interface {
+	GetParameter(ctx context.Context, input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error)
+	GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error)
+}
+
+// readParameter returns the value of the parameter, decrypting SecureString
+// parameters. The version is either a version number or a label.
+func (a *AWS) readParameter(ctx context.Context, name, version string) ([]byte, error) {
+	if version != "" {
+		name = fmt.Sprintf("%s:%s", name, version)
+	}
+	resp, err := a.ssm.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting parameter: %w", err)
+	}
+	if resp.Parameter == nil || resp.Parameter.Value == nil {
+		return nil, fmt.Errorf("parameter %q has no value", name)
+	}
+	return []byte(*resp.Parameter.Value), nil
+}
+
+// readParametersByPath returns all parameters below the path, keyed by their
+// name relative to the path with slashes replaced by underscores, e.g.
+// "db_password" for "/app/db/password" below "/app". Parameters whose keys
+// collide, e.g. "/app/db/password" and "/app/db_password", are rejected.
+func (a *AWS) readParametersByPath(ctx context.Context, path, version string) (map[string][]byte, error) {
+	if path == "" {
+		return nil, fmt.Errorf("parameter path must not be empty")
+	}
+	if version != "" {
+		return nil, fmt.Errorf("version is not supported for parameter paths")
+	}
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	input := &ssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	}
+	data := make(map[string][]byte)
+	names := make(map[string]string)
+	for {
+		resp, err := a.ssm.GetParametersByPath(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error getting parameters by path: %w", err)
+		}
+		for _, param := range resp.Parameters {
+			if param.Name == nil || param.Value == nil {
+				continue
+			}
+			key := strings.ReplaceAll(strings.TrimPrefix(*param.Name, prefix), "/", "_")
+			if name, ok := names[key]; ok {
+				return nil, fmt.Errorf("parameters %q and %q have the same key %q", name, *param.Name, key)
+			}
+			names[key] = *param.Name
+			data[key] = []byte(*param.Value)
+		}
+		if resp.NextToken == nil || *resp.NextToken == "" {
+			break
+		}
+		input.NextToken = resp.NextToken
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no parameters found below path %q", path)
+	}
+	return data, nil
+}
+
+type parameterStoreClient struct {
+	client *ssm.Client
+}
+
+func (c *parameterStoreClient) GetParameter(ctx context.Context, input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+	resp, err := c.client.GetParameterRequest(input).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetParameterOutput, nil
+}
+
+func (c *parameterStoreClient) GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+	resp, err := c.client.GetParametersByPathRequest(input).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetParametersByPathOutput, nil
+}
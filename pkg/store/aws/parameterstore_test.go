@@ -0,0 +1,137 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store/aws/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AWS Parameter Store", func() {
+	var (
+		ctx    context.Context
+		client *fake.ParameterStoreClient
+		store  *AWS
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client = fake.NewFakeParameterStoreClient()
+		store = &AWS{ssm: client}
+	})
+
+	It("should return the decrypted parameter", func() {
+		client.GetParameterFn = func(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+			Expect(aws.StringValue(input.Name)).To(Equal("/app/db/password"))
+			Expect(aws.BoolValue(input.WithDecryption)).To(BeTrue())
+			return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{
+				Name:  input.Name,
+				Type:  ssm.ParameterTypeSecureString,
+				Value: aws.String("s3cr3t"),
+			}}, nil
+		}
+		value, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "/app/db/password"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(value)).To(Equal("s3cr3t"))
+	})
+
+	It("should select the version and property", func() {
+		client.GetParameterFn = func(input *ssm.GetParameterInput) (*ssm.GetParameterOutput, error) {
+			Expect(aws.StringValue(input.Name)).To(Equal("/app/db:3"))
+			return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{
+				Value: aws.String(`{"user":"admin"}`),
+			}}, nil
+		}
+		value, err := store.GetSecret(ctx, smv1alpha1.RemoteReference{
+			Name:     "/app/db",
+			Version:  aws.String("3"),
+			Property: aws.String("user"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(value)).To(Equal("admin"))
+	})
+
+	It("should fetch all parameters below a path", func() {
+		pages := []*ssm.GetParametersByPathOutput{
+			{
+				Parameters: []ssm.Parameter{
+					{Name: aws.String("/app/username"), Value: aws.String("admin")},
+					{Name: aws.String("/app/db/password"), Value: aws.String("s3cr3t")},
+				},
+				NextToken: aws.String("next"),
+			},
+			{
+				Parameters: []ssm.Parameter{
+					{Name: aws.String("/app/hosts"), Value: aws.String("db-0,db-1")},
+				},
+			},
+		}
+		client.GetParametersByPathFn = func(input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+			Expect(aws.StringValue(input.Path)).To(Equal("/app/"))
+			Expect(aws.BoolValue(input.Recursive)).To(BeTrue())
+			Expect(aws.BoolValue(input.WithDecryption)).To(BeTrue())
+			page := pages[0]
+			pages = pages[1:]
+			return page, nil
+		}
+		values, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "/app/"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(Equal(map[string][]byte{
+			"username":    []byte("admin"),
+			"db_password": []byte("s3cr3t"),
+			"hosts":       []byte("db-0,db-1"),
+		}))
+		Expect(pages).To(BeEmpty())
+	})
+
+	It("should reject parameters with the same key", func() {
+		client.GetParametersByPathFn = func(input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+			return &ssm.GetParametersByPathOutput{
+				Parameters: []ssm.Parameter{
+					{Name: aws.String("/app/db/password"), Value: aws.String("s3cr3t")},
+					{Name: aws.String("/app/db_password"), Value: aws.String("other")},
+				},
+			}, nil
+		}
+		_, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "/app"})
+		Expect(err).To(MatchError(ContainSubstring(`same key "db_password"`)))
+	})
+
+	It("should fail without parameters below the path", func() {
+		client.GetParametersByPathFn = func(input *ssm.GetParametersByPathInput) (*ssm.GetParametersByPathOutput, error) {
+			return &ssm.GetParametersByPathOutput{}, nil
+		}
+		_, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "/app"})
+		Expect(err).To(MatchError(ContainSubstring(`no parameters found below path "/app"`)))
+	})
+
+	It("should reject an empty parameter path", func() {
+		_, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: ""})
+		Expect(err).To(MatchError(ContainSubstring("must not be empty")))
+	})
+
+	It("should reject a version for parameter paths", func() {
+		_, err := store.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "/app", Version: aws.String("3")})
+		Expect(err).To(MatchError(ContainSubstring("version is not supported")))
+	})
+})
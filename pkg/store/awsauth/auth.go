@@ -0,0 +1,88 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package awsauth configures AWS credentials from the auth section of a
+// store. It is shared by the AWS store and the AWS IAM auth method of Vault.
+package awsauth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
+)
+
+// STSEndpoint is the environment variable overriding the STS endpoint.
+const STSEndpoint = "AWS_STS_ENDPOINT"
+
+// ConfigureAuth sets the credentials of the config from the auth section of a
+// store. Referenced Secrets and ServiceAccounts are resolved with the resolver
+// of the store.
+func ConfigureAuth(ctx context.Context, cfg *aws.Config, store smv1alpha1.GenericStore, auth *smv1alpha1.AWSAuth, resolver *resolver.Resolver) error {
+	key := newStoreKey(store)
+	if auth.JWT != nil {
+		provider, err := newWebIdentityRoleProvider(newSTSClient(*cfg), key, auth.JWT, resolver)
+		if err != nil {
+			return err
+		}
+		cfg.Credentials = provider
+	} else {
+		if auth.AccessKeyID == nil || auth.SecretAccessKey == nil {
+			return fmt.Errorf("missing accessKeyID/secretAccessKey in store config")
+		}
+		aKid, err := resolver.SecretKey(ctx, *auth.AccessKeyID)
+		if err != nil {
+			return err
+		}
+		sak, err := resolver.SecretKey(ctx, *auth.SecretAccessKey)
+		if err != nil {
+			return err
+		}
+		nScp := aws.NewStaticCredentialsProvider(aKid, sak, "secret-manager")
+		cfg.Credentials = nScp
+	}
+	if auth.Role != nil {
+		role, err := resolver.SecretKey(ctx, *auth.Role)
+		if err != nil {
+			return err
+		}
+		cfg.Credentials = newAssumeRoleProvider(*cfg, key, smv1alpha1.AWSAssumeRole{Role: role})
+	}
+	return nil
+}
+
+// newWebIdentityRoleProvider returns a credentials provider assuming the
+// configured role with a token of the referenced ServiceAccount. The
+// credentials are shared with all clients of the store using the same role
+// and ServiceAccount.
+func newWebIdentityRoleProvider(client STSClient, store storeKey, jwt *smv1alpha1.AWSJWTAuth, resolver *resolver.Resolver) (*webIdentityRoleProvider, error) {
+	namespace, err := resolver.Namespace(jwt.ServiceAccountRef.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("web-identity:%s:%s/%s", jwt.Role, namespace, jwt.ServiceAccountRef.Name)
+	return &webIdentityRoleProvider{
+		credentialsCache: sharedCredentials.get(store, key),
+		key:              key,
+		client:           client,
+		roleARN:          jwt.Role,
+		sessionName:      defaultSessionName,
+		token: func(ctx context.Context) (string, error) {
+			return resolver.ServiceAccountToken(ctx, jwt.ServiceAccountRef, []string{DefaultWebIdentityAudience})
+		},
+	}, nil
+}
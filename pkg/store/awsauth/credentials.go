@@ -0,0 +1,288 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// DefaultWebIdentityAudience is the audience of the ServiceAccount tokens
+	// exchanged for AWS credentials.
+	DefaultWebIdentityAudience = "sts.amazonaws.com"
+
+	defaultSessionName = "secret-manager"
+
+	// credentials are refreshed this long before they expire
+	credentialsExpiryWindow = time.Minute
+)
+
+// STSClient is the subset of the STS API used to assume roles.
+type STSClient interface {
+	AssumeRole(ctx context.Context, input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error)
+	AssumeRoleWithWebIdentity(ctx context.Context, input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error)
+}
+
+// newSTSClient returns an STS client authenticating with the credentials of
+// the config.
+var newSTSClient = func(cfg aws.Config) STSClient {
+	return &stsClient{client: sts.New(cfg)}
+}
+
+// credentialsCache caches credentials until shortly before they expire.
+type credentialsCache struct {
+	mu    sync.Mutex
+	creds *aws.Credentials
+}
+
+// storeKey identifies the store credentials are cached for. Cached
+// credentials are never shared between stores, so a store cannot use the
+// credentials of another store by referencing the same role.
+type storeKey struct {
+	kind string
+	name types.NamespacedName
+}
+
+func newStoreKey(store smv1alpha1.GenericStore) storeKey {
+	kind := smv1alpha1.SecretStoreKind
+	if _, ok := store.(*smv1alpha1.ClusterSecretStore); ok {
+		kind = smv1alpha1.ClusterSecretStoreKind
+	}
+	return storeKey{
+		kind: kind,
+		name: types.NamespacedName{
+			Namespace: store.GetNamespace(),
+			Name:      store.GetName(),
+		},
+	}
+}
+
+// credentialsCaches holds the credentials caches shared by the store clients,
+// which are created for every sync. The caches of a store are keyed by the
+// source of the credentials, e.g. the role and the ServiceAccount of a web
+// identity.
+type credentialsCaches struct {
+	mu     sync.Mutex
+	caches map[storeKey]map[string]*credentialsCache
+}
+
+var sharedCredentials = &credentialsCaches{caches: make(map[storeKey]map[string]*credentialsCache)}
+
+func (c *credentialsCaches) get(store storeKey, key string) *credentialsCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	caches, ok := c.caches[store]
+	if !ok {
+		caches = make(map[string]*credentialsCache)
+		c.caches[store] = caches
+	}
+	cache, ok := caches[key]
+	if !ok {
+		cache = &credentialsCache{}
+		caches[key] = cache
+	}
+	return cache
+}
+
+// Release removes the credentials cached for the deleted store of the given
+// kind and name.
+func Release(kind string, name types.NamespacedName) {
+	sharedCredentials.mu.Lock()
+	defer sharedCredentials.mu.Unlock()
+	delete(sharedCredentials.caches, storeKey{kind: kind, name: name})
+}
+
+// credentialsKey identifies the credentials of the provider. Credentials
+// assumed with them are cached by this key. Static credentials are identified
+// by a hash of all their values, as STS only verifies the secret key when
+// assuming a role and not when cached credentials are reused.
+func credentialsKey(provider aws.CredentialsProvider) string {
+	switch p := provider.(type) {
+	case aws.StaticCredentialsProvider:
+		hash := sha256.Sum256([]byte(strings.Join([]string{
+			p.Value.AccessKeyID,
+			p.Value.SecretAccessKey,
+			p.Value.SessionToken,
+		}, "\x00")))
+		return fmt.Sprintf("static:%x", hash)
+	case *assumeRoleProvider:
+		return p.key
+	case *webIdentityRoleProvider:
+		return p.key
+	}
+	return "default"
+}
+
+func (c *credentialsCache) retrieve(ctx context.Context, fn func(ctx context.Context) (aws.Credentials, error)) (aws.Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.creds != nil && time.Now().Add(credentialsExpiryWindow).Before(c.creds.Expires) {
+		return *c.creds, nil
+	}
+	creds, err := fn(ctx)
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	c.creds = &creds
+	return creds, nil
+}
+
+// assumeRoleProvider retrieves credentials by assuming a role with the
+// credentials of the STS client.
+type assumeRoleProvider struct {
+	*credentialsCache
+	key    string
+	client STSClient
+	role   smv1alpha1.AWSAssumeRole
+}
+
+// newAssumeRoleProvider returns a provider assuming the role with the
+// credentials of the config. The credentials are shared with all providers
+// of the store assuming the same role with the same credentials.
+func newAssumeRoleProvider(cfg aws.Config, store storeKey, role smv1alpha1.AWSAssumeRole) *assumeRoleProvider {
+	var duration time.Duration
+	if role.Duration != nil {
+		duration = role.Duration.Duration
+	}
+	key := fmt.Sprintf("%s|role:%s:%s:%s:%s:%v", credentialsKey(cfg.Credentials),
+		role.Role, role.SessionName, role.ExternalID, duration, role.SessionTags)
+	return &assumeRoleProvider{
+		credentialsCache: sharedCredentials.get(store, key),
+		key:              key,
+		client:           newSTSClient(cfg),
+		role:             role,
+	}
+}
+
+// AssumeRoles sets the credentials of the config to the last of the roles,
+// every role is assumed with the credentials of the previous one.
+func AssumeRoles(cfg *aws.Config, store smv1alpha1.GenericStore, roles []smv1alpha1.AWSAssumeRole) {
+	key := newStoreKey(store)
+	for _, role := range roles {
+		cfg.Credentials = newAssumeRoleProvider(*cfg, key, role)
+	}
+}
+
+var _ aws.CredentialsProvider = &assumeRoleProvider{}
+
+func (p *assumeRoleProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	return p.retrieve(ctx, p.assumeRole)
+}
+
+func (p *assumeRoleProvider) assumeRole(ctx context.Context) (aws.Credentials, error) {
+	input := &sts.AssumeRoleInput{
+		RoleArn:         aws.String(p.role.Role),
+		RoleSessionName: aws.String(defaultSessionName),
+	}
+	if p.role.SessionName != "" {
+		input.RoleSessionName = aws.String(p.role.SessionName)
+	}
+	if p.role.ExternalID != "" {
+		input.ExternalId = aws.String(p.role.ExternalID)
+	}
+	if p.role.Duration != nil {
+		input.DurationSeconds = aws.Int64(int64(p.role.Duration.Seconds()))
+	}
+	for _, tag := range p.role.SessionTags {
+		input.Tags = append(input.Tags, sts.Tag{
+			Key:   aws.String(tag.Key),
+			Value: aws.String(tag.Value),
+		})
+	}
+	resp, err := p.client.AssumeRole(ctx, input)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("unable to assume role %q: %w", p.role.Role, err)
+	}
+	return credentialsFromSTS(resp.Credentials, "AssumeRoleProvider"), nil
+}
+
+// webIdentityRoleProvider retrieves credentials by assuming a role with the
+// token of a Kubernetes ServiceAccount.
+type webIdentityRoleProvider struct {
+	*credentialsCache
+	key         string
+	client      STSClient
+	roleARN     string
+	sessionName string
+	token       func(ctx context.Context) (string, error)
+}
+
+var _ aws.CredentialsProvider = &webIdentityRoleProvider{}
+
+func (p *webIdentityRoleProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	return p.retrieve(ctx, p.assumeRoleWithWebIdentity)
+}
+
+func (p *webIdentityRoleProvider) assumeRoleWithWebIdentity(ctx context.Context) (aws.Credentials, error) {
+	token, err := p.token(ctx)
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	resp, err := p.client.AssumeRoleWithWebIdentity(ctx, &sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(p.roleARN),
+		RoleSessionName:  aws.String(p.sessionName),
+		WebIdentityToken: aws.String(token),
+	})
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("unable to assume role %q with web identity: %w", p.roleARN, err)
+	}
+	return credentialsFromSTS(resp.Credentials, "WebIdentityCredentials"), nil
+}
+
+func credentialsFromSTS(creds *sts.Credentials, source string) aws.Credentials {
+	if creds == nil {
+		return aws.Credentials{Source: source}
+	}
+	return aws.Credentials{
+		AccessKeyID:     aws.StringValue(creds.AccessKeyId),
+		SecretAccessKey: aws.StringValue(creds.SecretAccessKey),
+		SessionToken:    aws.StringValue(creds.SessionToken),
+		Source:          source,
+		CanExpire:       true,
+		Expires:         aws.TimeValue(creds.Expiration),
+	}
+}
+
+type stsClient struct {
+	client *sts.Client
+}
+
+func (c *stsClient) AssumeRole(ctx context.Context, input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	resp, err := c.client.AssumeRoleRequest(input).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.AssumeRoleOutput, nil
+}
+
+func (c *stsClient) AssumeRoleWithWebIdentity(ctx context.Context, input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	resp, err := c.client.AssumeRoleWithWebIdentityRequest(input).Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.AssumeRoleWithWebIdentityOutput, nil
+}
@@ -0,0 +1,249 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package awsauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store/awsauth/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("AWS web identity credentials", func() {
+	var (
+		ctx      context.Context
+		client   *fake.STSClient
+		provider *webIdentityRoleProvider
+		requests int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		requests = 0
+		client = fake.NewFakeSTSClient()
+		client.AssumeRoleWithWebIdentityFn = func(input *sts.AssumeRoleWithWebIdentityInput) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+			requests++
+			Expect(aws.StringValue(input.RoleArn)).To(Equal("arn:aws:iam::123456789012:role/team-a"))
+			Expect(aws.StringValue(input.RoleSessionName)).To(Equal(defaultSessionName))
+			Expect(aws.StringValue(input.WebIdentityToken)).To(Equal("token"))
+			return &sts.AssumeRoleWithWebIdentityOutput{Credentials: &sts.Credentials{
+				AccessKeyId:     aws.String("AKID"),
+				SecretAccessKey: aws.String("SECRET"),
+				SessionToken:    aws.String("SESSION"),
+				Expiration:      aws.Time(time.Now().Add(time.Hour)),
+			}}, nil
+		}
+		provider = &webIdentityRoleProvider{
+			credentialsCache: &credentialsCache{},
+			client:           client,
+			roleARN:          "arn:aws:iam::123456789012:role/team-a",
+			sessionName:      defaultSessionName,
+			token: func(ctx context.Context) (string, error) {
+				return "token", nil
+			},
+		}
+	})
+
+	It("should exchange the token for credentials and cache them", func() {
+		creds, err := provider.Retrieve(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.AccessKeyID).To(Equal("AKID"))
+		Expect(creds.SecretAccessKey).To(Equal("SECRET"))
+		Expect(creds.SessionToken).To(Equal("SESSION"))
+		Expect(creds.CanExpire).To(BeTrue())
+
+		_, err = provider.Retrieve(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal(1))
+	})
+
+	It("should refresh credentials about to expire", func() {
+		provider.creds = &aws.Credentials{Expires: time.Now().Add(credentialsExpiryWindow / 2)}
+		_, err := provider.Retrieve(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal(1))
+	})
+
+	It("should fail if no token can be requested", func() {
+		provider.token = func(ctx context.Context) (string, error) {
+			return "", errors.New("forbidden")
+		}
+		_, err := provider.Retrieve(ctx)
+		Expect(err).To(MatchError("forbidden"))
+		Expect(requests).To(BeZero())
+	})
+})
+
+var _ = Describe("AWS assume role credentials", func() {
+	var (
+		ctx      context.Context
+		client   *fake.STSClient
+		input    *sts.AssumeRoleInput
+		requests int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		input = nil
+		requests = 0
+		client = fake.NewFakeSTSClient()
+		client.AssumeRoleFn = func(in *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+			input = in
+			requests++
+			return &sts.AssumeRoleOutput{Credentials: &sts.Credentials{
+				AccessKeyId:     aws.String("AKID"),
+				SecretAccessKey: aws.String("SECRET"),
+				SessionToken:    aws.String("SESSION"),
+				Expiration:      aws.Time(time.Now().Add(time.Hour)),
+			}}, nil
+		}
+	})
+
+	It("should assume the role with the default session name", func() {
+		provider := &assumeRoleProvider{
+			credentialsCache: &credentialsCache{},
+			client:           client,
+			role:             smv1alpha1.AWSAssumeRole{Role: "arn:aws:iam::123456789012:role/team-a"},
+		}
+		creds, err := provider.Retrieve(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.SessionToken).To(Equal("SESSION"))
+		Expect(aws.StringValue(input.RoleArn)).To(Equal("arn:aws:iam::123456789012:role/team-a"))
+		Expect(aws.StringValue(input.RoleSessionName)).To(Equal(defaultSessionName))
+		Expect(input.ExternalId).To(BeNil())
+		Expect(input.DurationSeconds).To(BeNil())
+		Expect(input.Tags).To(BeEmpty())
+	})
+
+	It("should pass the role options", func() {
+		provider := &assumeRoleProvider{
+			credentialsCache: &credentialsCache{},
+			client:           client,
+			role: smv1alpha1.AWSAssumeRole{
+				Role:        "arn:aws:iam::123456789012:role/team-a",
+				ExternalID:  "external-id",
+				SessionName: "team-a-sync",
+				SessionTags: []smv1alpha1.AWSSessionTag{{Key: "team", Value: "a"}},
+				Duration:    &metav1.Duration{Duration: 30 * time.Minute},
+			},
+		}
+		_, err := provider.Retrieve(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(aws.StringValue(input.RoleSessionName)).To(Equal("team-a-sync"))
+		Expect(aws.StringValue(input.ExternalId)).To(Equal("external-id"))
+		Expect(aws.Int64Value(input.DurationSeconds)).To(Equal(int64(1800)))
+		Expect(input.Tags).To(HaveLen(1))
+		Expect(aws.StringValue(input.Tags[0].Key)).To(Equal("team"))
+		Expect(aws.StringValue(input.Tags[0].Value)).To(Equal("a"))
+	})
+
+	It("should share the credentials of providers of a store assuming the same role", func() {
+		role := smv1alpha1.AWSAssumeRole{Role: "arn:aws:iam::123456789012:role/shared"}
+		teamA := storeKey{kind: smv1alpha1.SecretStoreKind, name: types.NamespacedName{Namespace: "team-a", Name: "aws"}}
+		teamB := storeKey{kind: smv1alpha1.SecretStoreKind, name: types.NamespacedName{Namespace: "team-b", Name: "aws"}}
+		defer Release(teamA.kind, teamA.name)
+		defer Release(teamB.kind, teamB.name)
+		retrieve := func(cfg aws.Config, store storeKey) {
+			provider := newAssumeRoleProvider(cfg, store, role)
+			provider.client = client
+			_, err := provider.Retrieve(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		cfg := aws.Config{Credentials: aws.NewStaticCredentialsProvider("BASE", "SECRET", "")}
+		retrieve(cfg, teamA)
+		retrieve(cfg, teamA)
+		Expect(requests).To(Equal(1), "the credentials should be cached across providers")
+
+		retrieve(aws.Config{Credentials: aws.NewStaticCredentialsProvider("OTHER", "SECRET", "")}, teamA)
+		Expect(requests).To(Equal(2), "other base credentials should not share the cache")
+
+		retrieve(aws.Config{Credentials: aws.NewStaticCredentialsProvider("BASE", "WRONG", "")}, teamA)
+		Expect(requests).To(Equal(3), "another secret key should not share the cache")
+
+		retrieve(cfg, teamB)
+		Expect(requests).To(Equal(4), "other stores should not share the cache")
+
+		Release(teamA.kind, teamA.name)
+		retrieve(cfg, teamA)
+		Expect(requests).To(Equal(5), "the credentials of a released store should be forgotten")
+	})
+
+	It("should assume every role of the chain with the credentials of the previous role", func() {
+		defer func(fn func(aws.Config) STSClient) { newSTSClient = fn }(newSTSClient)
+		var callers []string
+		newSTSClient = func(cfg aws.Config) STSClient {
+			hop := fake.NewFakeSTSClient()
+			hop.AssumeRoleFn = func(in *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+				creds, err := cfg.Credentials.Retrieve(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				callers = append(callers, creds.AccessKeyID)
+				return &sts.AssumeRoleOutput{Credentials: &sts.Credentials{
+					AccessKeyId:     in.RoleArn,
+					SecretAccessKey: aws.String("SECRET"),
+					SessionToken:    aws.String("SESSION"),
+					Expiration:      aws.Time(time.Now().Add(time.Hour)),
+				}}, nil
+			}
+			return hop
+		}
+
+		cfg := aws.Config{Credentials: aws.NewStaticCredentialsProvider("CHAIN", "SECRET", "")}
+		AssumeRoles(&cfg, &smv1alpha1.SecretStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "chain", Namespace: "default"},
+		}, []smv1alpha1.AWSAssumeRole{
+			{Role: "arn:aws:iam::111111111111:role/broker"},
+			{Role: "arn:aws:iam::222222222222:role/team-a"},
+			{Role: "arn:aws:iam::333333333333:role/team-b"},
+		})
+		creds, err := cfg.Credentials.Retrieve(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(creds.AccessKeyID).To(Equal("arn:aws:iam::333333333333:role/team-b"))
+		Expect(callers).To(Equal([]string{
+			"CHAIN",
+			"arn:aws:iam::111111111111:role/broker",
+			"arn:aws:iam::222222222222:role/team-a",
+		}))
+	})
+
+	It("should return the roles to assume in order", func() {
+		spec := &smv1alpha1.AWSStore{
+			Role:       "arn:aws:iam::111111111111:role/broker",
+			ExternalID: "external-id",
+			RoleChain: []smv1alpha1.AWSAssumeRole{
+				{Role: "arn:aws:iam::222222222222:role/team-a"},
+			},
+		}
+		roles := spec.AssumeRoles()
+		Expect(roles).To(HaveLen(2))
+		Expect(roles[0].Role).To(Equal("arn:aws:iam::111111111111:role/broker"))
+		Expect(roles[0].ExternalID).To(Equal("external-id"))
+		Expect(roles[1].Role).To(Equal("arn:aws:iam::222222222222:role/team-a"))
+
+		spec.Role = ""
+		Expect(spec.AssumeRoles()).To(HaveLen(1))
+	})
+})
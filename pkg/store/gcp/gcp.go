@@ -22,29 +22,25 @@ import (
 
 	"github.com/go-logr/logr"
 
-	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
 	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
 	ctxlog "github.com/itscontained/secret-manager/pkg/log"
 	"github.com/itscontained/secret-manager/pkg/store"
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
 	"github.com/itscontained/secret-manager/pkg/store/schema"
 
 	"google.golang.org/api/option"
 	"google.golang.org/api/secretmanager/v1"
 
-	corev1 "k8s.io/api/core/v1"
-
-	"k8s.io/apimachinery/pkg/types"
-
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ store.Client = &GCP{}
 
 type GCP struct {
-	kube   ctrlclient.Client
-	store  smv1alpha1.GenericStore
-	log    logr.Logger
-	client *secretmanager.Service
+	store    smv1alpha1.GenericStore
+	resolver *resolver.Resolver
+	log      logr.Logger
+	client   *secretmanager.Service
 }
 
 func init() {
@@ -56,9 +52,9 @@ func init() {
 func (g *GCP) New(ctx context.Context, store smv1alpha1.GenericStore, kube ctrlclient.Client, namespace string) (store.Client, error) {
 	log := ctxlog.FromContext(ctx)
 	gcpClient := &GCP{
-		kube:  kube,
-		store: store,
-		log:   log,
+		store:    store,
+		resolver: resolver.New(kube, store, namespace),
+		log:      log,
 	}
 	err := gcpClient.newClient(ctx)
 	if err != nil {
@@ -69,7 +65,7 @@ func (g *GCP) New(ctx context.Context, store smv1alpha1.GenericStore, kube ctrlc
 }
 
 func (g *GCP) GetSecret(ctx context.Context, ref smv1alpha1.RemoteReference) ([]byte, error) {
-	version := "latest"
+	version := smv1alpha1.DefaultGCPSecretVersion
 	if ref.Version != nil {
 		version = *ref.Version
 	}
@@ -81,7 +77,7 @@ func (g *GCP) GetSecret(ctx context.Context, ref smv1alpha1.RemoteReference) ([]
 }
 
 func (g *GCP) GetSecretMap(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string][]byte, error) {
-	version := "latest"
+	version := smv1alpha1.DefaultGCPSecretVersion
 	if ref.Version != nil {
 		version = *ref.Version
 	}
@@ -118,7 +114,6 @@ func (g *GCP) newClient(ctx context.Context) error {
 		}
 		return nil
 	}
-	// TODO: Validating Webhook Candidate
 	if spec.AuthSecretRef.JSON != nil && spec.AuthSecretRef.FilePath != nil {
 		return fmt.Errorf("multiple authentication methods configured")
 	}
@@ -126,22 +121,10 @@ func (g *GCP) newClient(ctx context.Context) error {
 		g.log.V(1).Info("file authentication defined. using %s", *spec.AuthSecretRef.FilePath)
 		clientOption = option.WithCredentialsFile(*spec.AuthSecretRef.FilePath)
 	}
-	scoped := true
-	if g.store.GetTypeMeta().Kind == smv1alpha1.ClusterSecretStoreKind {
-		g.log.V(1).Info("removing namespace scope restriction")
-		scoped = false
-	}
 	if spec.AuthSecretRef.JSON != nil {
 		g.log.V(1).Info("JSON authentication defined")
-		namespace := g.store.GetNamespace()
-		if !scoped {
-			if spec.AuthSecretRef.JSON.Namespace == nil {
-				return fmt.Errorf("authsecretref namespace required when cluster-scoped")
-			}
-			namespace = *spec.AuthSecretRef.JSON.Namespace
-		}
-		data, e := g.secretKeyRef(ctx, namespace, *spec.AuthSecretRef.JSON)
-		if e != nil {
+		data, err := g.resolver.SecretKey(ctx, *spec.AuthSecretRef.JSON)
+		if err != nil {
 			return err
 		}
 		clientOption = option.WithCredentialsJSON([]byte(data))
@@ -152,22 +135,3 @@ func (g *GCP) newClient(ctx context.Context) error {
 	}
 	return nil
 }
-
-func (g *GCP) secretKeyRef(ctx context.Context, namespace string, secretRef smmeta.SecretKeySelector) (string, error) {
-	g.log.V(1).Info("retrieving kubernetes secret", "name", secretRef.Name)
-	var secret corev1.Secret
-	ref := types.NamespacedName{
-		Namespace: namespace,
-		Name:      secretRef.Name,
-	}
-	err := g.kube.Get(ctx, ref, &secret)
-	if err != nil {
-		return "", err
-	}
-	keyBytes, ok := secret.Data[secretRef.Key]
-	if !ok {
-		return "", fmt.Errorf("no data for %q in secret '%s/%s'", secretRef.Key, secretRef.Name, namespace)
-	}
-	value := strings.TrimSpace(string(keyBytes))
-	return value, nil
-}
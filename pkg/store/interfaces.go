@@ -16,9 +16,12 @@ package store
 
 import (
 	"context"
+	"time"
 
 	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
 
+	"k8s.io/apimachinery/pkg/types"
+
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -28,3 +31,24 @@ type Client interface {
 	GetSecret(ctx context.Context, ref smv1alpha1.RemoteReference) ([]byte, error)
 	GetSecretMap(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string][]byte, error)
 }
+
+// Checker is implemented by store clients which can verify that the store
+// backend is reachable and that the configured credentials are valid.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// Refresher is implemented by store clients which fetch secrets that expire,
+// e.g. dynamic credentials with a lease. RefreshTime returns the time by which
+// the secrets fetched by the client have to be fetched again, or the zero time
+// if none of them expire.
+type Refresher interface {
+	RefreshTime() time.Time
+}
+
+// Releaser is implemented by store clients which hold resources for a store,
+// e.g. cached credentials, that have to be released once the store is deleted.
+// It is called on the registered store client of every backend.
+type Releaser interface {
+	Release(ctx context.Context, kind string, name types.NamespacedName) error
+}
@@ -0,0 +1,122 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/util/serviceaccount"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrCrossNamespaceRef is returned for references of a SecretStore to
+// objects in another namespace.
+var ErrCrossNamespaceRef = errors.New("cross-namespace references are not allowed for SecretStores")
+
+// ErrNamespaceRequired is returned for references of a ClusterSecretStore
+// without namespace if the store is not used from a namespace, e.g. when the
+// store itself is validated.
+var ErrNamespaceRequired = fmt.Errorf("namespace is required for references of a %s", smv1alpha1.ClusterSecretStoreKind)
+
+// Resolver resolves the Kubernetes objects referenced by a store, e.g. the
+// Secrets holding credentials for the store backend.
+//
+// References of a SecretStore are resolved in the namespace of the store.
+// References of a ClusterSecretStore are resolved in their own namespace or,
+// if not set, in the namespace the store is used from.
+type Resolver struct {
+	kube      ctrlclient.Client
+	store     smv1alpha1.GenericStore
+	namespace string
+}
+
+// New returns a Resolver for the references of the store when used from the
+// given namespace, i.e. the namespace of the ExternalSecret.
+func New(kube ctrlclient.Client, store smv1alpha1.GenericStore, namespace string) *Resolver {
+	return &Resolver{
+		kube:      kube,
+		store:     store,
+		namespace: namespace,
+	}
+}
+
+// Namespace returns the namespace of an object referenced by the store with
+// the given optional namespace.
+func (r *Resolver) Namespace(namespace *string) (string, error) {
+	ref := smmeta.StringValue(namespace)
+	if _, ok := r.store.(*smv1alpha1.ClusterSecretStore); !ok {
+		if ref != "" && ref != r.store.GetNamespace() {
+			return "", fmt.Errorf("%w: %q", ErrCrossNamespaceRef, ref)
+		}
+		return r.store.GetNamespace(), nil
+	}
+	if ref != "" {
+		return ref, nil
+	}
+	if r.namespace == "" {
+		return "", ErrNamespaceRequired
+	}
+	return r.namespace, nil
+}
+
+// SecretKey returns the value of the referenced key of a Secret without
+// leading and trailing whitespace.
+func (r *Resolver) SecretKey(ctx context.Context, selector smmeta.SecretKeySelector) (string, error) {
+	namespace, err := r.Namespace(selector.Namespace)
+	if err != nil {
+		return "", err
+	}
+	secret := &corev1.Secret{}
+	ref := types.NamespacedName{
+		Namespace: namespace,
+		Name:      selector.Name,
+	}
+	if err := r.kube.Get(ctx, ref, secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return "", fmt.Errorf("no data for %q in secret '%s'", selector.Key, ref)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// ServiceAccountToken requests a token for the referenced ServiceAccount,
+// with the given audiences if the selector does not set any.
+func (r *Resolver) ServiceAccountToken(ctx context.Context, selector smmeta.ServiceAccountSelector, defaultAudiences []string) (string, error) {
+	namespace, err := r.Namespace(selector.Namespace)
+	if err != nil {
+		return "", err
+	}
+	audiences := selector.Audiences
+	if len(audiences) == 0 {
+		audiences = defaultAudiences
+	}
+	ref := types.NamespacedName{
+		Namespace: namespace,
+		Name:      selector.Name,
+	}
+	return serviceaccount.Token(ctx, r.kube, ref, audiences)
+}
@@ -0,0 +1,157 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/util/serviceaccount"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Resolver", func() {
+	secretStore := &smv1alpha1.SecretStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "store", Namespace: "team-a"},
+	}
+	clusterStore := &smv1alpha1.ClusterSecretStore{
+		ObjectMeta: metav1.ObjectMeta{Name: "store"},
+	}
+	ns := func(namespace string) *string {
+		return &namespace
+	}
+
+	DescribeTable("Namespace",
+		func(store smv1alpha1.GenericStore, namespace string, ref *string, expected string, expectedErr string) {
+			actual, err := New(nil, store, namespace).Namespace(ref)
+			if expectedErr != "" {
+				Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actual).To(Equal(expected))
+		},
+		Entry("SecretStore without namespace", secretStore, "team-a", nil, "team-a", ""),
+		Entry("SecretStore with its own namespace", secretStore, "team-a", ns("team-a"), "team-a", ""),
+		Entry("SecretStore with another namespace", secretStore, "team-a", ns("team-b"), "", "cross-namespace references are not allowed"),
+		Entry("SecretStore checked by the store controller", secretStore, "", nil, "team-a", ""),
+		Entry("ClusterSecretStore without namespace", clusterStore, "team-a", nil, "team-a", ""),
+		Entry("ClusterSecretStore with namespace", clusterStore, "team-a", ns("secret-manager"), "secret-manager", ""),
+		Entry("ClusterSecretStore checked by the store controller", clusterStore, "", ns("secret-manager"), "secret-manager", ""),
+		Entry("ClusterSecretStore without any namespace", clusterStore, "", nil, "", "namespace is required"),
+	)
+
+	Context("SecretKey", func() {
+		var kube = ctrlfake.NewFakeClient(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "team-a"},
+				Data:       map[string][]byte{"token": []byte("team-a-token\n")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "secret-manager"},
+				Data:       map[string][]byte{"token": []byte("shared-token")},
+			},
+		)
+		selector := func(namespace *string, key string) smmeta.SecretKeySelector {
+			return smmeta.SecretKeySelector{
+				LocalObjectReference: smmeta.LocalObjectReference{Name: "credentials"},
+				Namespace:            namespace,
+				Key:                  key,
+			}
+		}
+
+		DescribeTable("should resolve the referenced secret",
+			func(store smv1alpha1.GenericStore, ref smmeta.SecretKeySelector, expected string, expectedErr string) {
+				actual, err := New(kube, store, "team-a").SecretKey(context.Background(), ref)
+				if expectedErr != "" {
+					Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actual).To(Equal(expected))
+			},
+			Entry("SecretStore", secretStore, selector(nil, "token"), "team-a-token", ""),
+			Entry("SecretStore with another namespace", secretStore, selector(ns("secret-manager"), "token"), "", "cross-namespace references"),
+			Entry("SecretStore with missing key", secretStore, selector(nil, "password"), "", `no data for "password" in secret 'team-a/credentials'`),
+			Entry("ClusterSecretStore", clusterStore, selector(nil, "token"), "team-a-token", ""),
+			Entry("ClusterSecretStore with namespace", clusterStore, selector(ns("secret-manager"), "token"), "shared-token", ""),
+		)
+	})
+
+	Context("ServiceAccountToken", func() {
+		var audiences []string
+		clientset := kubefake.NewSimpleClientset()
+		clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			create := action.(k8stesting.CreateAction)
+			request := create.GetObject().(*authenticationv1.TokenRequest)
+			audiences = request.Spec.Audiences
+			return true, &authenticationv1.TokenRequest{
+				Status: authenticationv1.TokenRequestStatus{Token: action.GetNamespace() + "-token"},
+			}, nil
+		})
+		kube := serviceaccount.NewClient(ctrlfake.NewFakeClient(), clientset.CoreV1())
+		selector := func(namespace *string) smmeta.ServiceAccountSelector {
+			return smmeta.ServiceAccountSelector{
+				Name:      "secret-reader",
+				Namespace: namespace,
+			}
+		}
+
+		DescribeTable("should resolve the ServiceAccount namespace",
+			func(store smv1alpha1.GenericStore, namespace string, ref smmeta.ServiceAccountSelector, expected string, expectedErr string) {
+				actual, err := New(kube, store, namespace).ServiceAccountToken(context.Background(), ref, []string{"vault"})
+				if expectedErr != "" {
+					Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actual).To(Equal(expected))
+			},
+			Entry("SecretStore", secretStore, "team-a", selector(nil), "team-a-token", ""),
+			Entry("SecretStore with another namespace", secretStore, "team-a", selector(ns("secret-manager")), "", "cross-namespace references"),
+			Entry("ClusterSecretStore", clusterStore, "team-a", selector(nil), "team-a-token", ""),
+			Entry("ClusterSecretStore with namespace", clusterStore, "team-a", selector(ns("secret-manager")), "secret-manager-token", ""),
+			Entry("ClusterSecretStore with namespace checked by the store controller", clusterStore, "", selector(ns("secret-manager")), "secret-manager-token", ""),
+			Entry("ClusterSecretStore without namespace checked by the store controller", clusterStore, "", selector(nil), "", "namespace is required"),
+		)
+
+		It("should request the default audiences unless the selector sets audiences", func() {
+			_, err := New(kube, secretStore, "team-a").ServiceAccountToken(context.Background(), selector(nil), []string{"vault"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]string{"vault"}))
+
+			ref := selector(nil)
+			ref.Audiences = []string{"custom"}
+			_, err = New(kube, secretStore, "team-a").ServiceAccountToken(context.Background(), ref, []string{"vault"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(audiences).To(Equal([]string{"custom"}))
+		})
+	})
+})
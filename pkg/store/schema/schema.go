@@ -33,7 +33,7 @@ func init() {
 // Register a store backend type. Register panics if a
 // backend with the same store is already registered
 func Register(s store.Client, storeSpec *smv1alpha1.SecretStoreSpec) {
-	storeName, err := getStoreBackend(storeSpec)
+	storeName, err := GetStoreBackend(storeSpec)
 	if err != nil {
 		panic(fmt.Sprintf("store error registering schema: %s", err.Error()))
 	}
@@ -51,7 +51,7 @@ func Register(s store.Client, storeSpec *smv1alpha1.SecretStoreSpec) {
 // ForceRegister adds to store schema, overwriting a store if
 // already registered. Should only be used for testing
 func ForceRegister(s store.Client, storeSpec *smv1alpha1.SecretStoreSpec) {
-	storeName, err := getStoreBackend(storeSpec)
+	storeName, err := GetStoreBackend(storeSpec)
 	if err != nil {
 		panic(fmt.Sprintf("store error registering schema: %s", err.Error()))
 	}
@@ -68,9 +68,20 @@ func GetStoreByName(name string) (store.Client, bool) {
 	return f, ok
 }
 
+// GetStores returns the store clients of all registered backends.
+func GetStores() []store.Client {
+	buildlock.RLock()
+	defer buildlock.RUnlock()
+	stores := make([]store.Client, 0, len(builder))
+	for _, s := range builder {
+		stores = append(stores, s)
+	}
+	return stores
+}
+
 func GetStore(s smv1alpha1.GenericStore) (store.Client, error) {
 	storeSpec := s.GetSpec()
-	storeName, err := getStoreBackend(storeSpec)
+	storeName, err := GetStoreBackend(storeSpec)
 	if err != nil {
 		return nil, fmt.Errorf("store error for %s: %w", s.GetName(), err)
 	}
@@ -86,7 +97,9 @@ func GetStore(s smv1alpha1.GenericStore) (store.Client, error) {
 	return f, nil
 }
 
-func getStoreBackend(storeSpec *smv1alpha1.SecretStoreSpec) (string, error) {
+// GetStoreBackend returns the name of the single backend configured in the
+// store spec, e.g: "vault".
+func GetStoreBackend(storeSpec *smv1alpha1.SecretStoreSpec) (string, error) {
 	storeBytes, err := json.Marshal(storeSpec)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal store spec: %w", err)
@@ -0,0 +1,277 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	ctxlog "github.com/itscontained/secret-manager/pkg/log"
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
+	"github.com/itscontained/secret-manager/pkg/store/vault/fake"
+	"github.com/itscontained/secret-manager/pkg/util/serviceaccount"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func selfSignedCertificate() (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "secret-manager"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+var _ = Describe("Vault auth methods", func() {
+	var (
+		ctx      context.Context
+		store    *smv1alpha1.SecretStore
+		secret   *corev1.Secret
+		client   *fake.Client
+		request  *vault.Request
+		body     map[string]string
+		newVault func() *Vault
+	)
+
+	secretRef := func(key string) smmeta.SecretKeySelector {
+		return smmeta.SecretKeySelector{
+			LocalObjectReference: smmeta.LocalObjectReference{Name: "vault-auth"},
+			Key:                  key,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = ctxlog.IntoContext(context.Background(), ctrllog.NullLogger{})
+		store = &smv1alpha1.SecretStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault", Namespace: "default"},
+			Spec: smv1alpha1.SecretStoreSpec{
+				Vault: &smv1alpha1.VaultStore{
+					Server: "https://vault.example.com",
+					Path:   "secret",
+				},
+			},
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault-auth", Namespace: "default"},
+			Data: map[string][]byte{
+				"jwt":               []byte("header.payload.signature"),
+				"password":          []byte("hunter2\n"),
+				"access-key-id":     []byte("AKIDEXAMPLE"),
+				"secret-access-key": []byte("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"),
+			},
+		}
+
+		request = nil
+		body = nil
+		client = fake.NewFakeClient()
+		client.RawRequestFn = func(r *vault.Request) (*vault.Response, error) {
+			request = r
+			Expect(json.Unmarshal(r.BodyBytes, &body)).To(Succeed())
+			return jsonResponse(`{"auth":{"client_token":"token","lease_duration":3600,"renewable":true}}`), nil
+		}
+		newVault = func() *Vault {
+			kube := ctrlfake.NewFakeClient(secret)
+			return &Vault{
+				store:     store,
+				namespace: "default",
+				resolver:  resolver.New(kube, store, "default"),
+				log:       ctxlog.FromContext(ctx),
+			}
+		}
+	})
+
+	It("should log in with a token requested for a ServiceAccount", func() {
+		clientset := kubefake.NewSimpleClientset()
+		var tokenRequest *authenticationv1.TokenRequest
+		clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			Expect(action.GetNamespace()).To(Equal("default"))
+			tokenRequest = action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+			return true, &authenticationv1.TokenRequest{
+				Status: authenticationv1.TokenRequestStatus{Token: "bound.service.account"},
+			}, nil
+		})
+
+		store.Spec.Vault.Auth.Kubernetes = &smv1alpha1.VaultKubernetesAuth{
+			Role:              "team-a",
+			ServiceAccountRef: &smmeta.ServiceAccountSelector{Name: "team-a"},
+		}
+		v := newVault()
+		v.resolver = resolver.New(serviceaccount.NewClient(ctrlfake.NewFakeClient(), clientset.CoreV1()), store, "default")
+		Expect(v.setToken(ctx, client)).To(Succeed())
+		Expect(request.URL.Path).To(Equal("/v1/auth/kubernetes/login"))
+		Expect(body).To(Equal(map[string]string{"jwt": "bound.service.account", "role": "team-a"}))
+		Expect(tokenRequest.Spec.Audiences).To(Equal([]string{DefaultJWTAudience}))
+	})
+
+	It("should not request a token for the Kubernetes API server", func() {
+		store.Spec.Vault.Auth.Kubernetes = &smv1alpha1.VaultKubernetesAuth{
+			Role: "team-a",
+			ServiceAccountRef: &smmeta.ServiceAccountSelector{
+				Name:      "team-a",
+				Audiences: []string{"https://kubernetes.default.svc.cluster.local"},
+			},
+		}
+		Expect(newVault().setToken(ctx, client)).To(MatchError(ContainSubstring("Kubernetes API server audience")))
+		Expect(request).To(BeNil())
+	})
+
+	It("should fail without a ServiceAccount token of the controller", func() {
+		defer func(path string) { serviceAccountTokenPath = path }(serviceAccountTokenPath)
+		serviceAccountTokenPath = filepath.Join(os.TempDir(), "secret-manager-missing-token")
+
+		store.Spec.Vault.Auth.Kubernetes = &smv1alpha1.VaultKubernetesAuth{Role: "team-a"}
+		Expect(newVault().setToken(ctx, client)).To(MatchError(ContainSubstring("could not get serviceaccount jwt from disk")))
+		Expect(request).To(BeNil())
+	})
+
+	It("should log in with a JWT", func() {
+		ref := secretRef("jwt")
+		store.Spec.Vault.Auth.JWT = &smv1alpha1.VaultJWTAuth{Path: "oidc", Role: "team-a", SecretRef: &ref}
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(client.Token()).To(Equal("token"))
+		Expect(request.URL.Path).To(Equal("/v1/auth/oidc/login"))
+		Expect(body).To(Equal(map[string]string{"jwt": "header.payload.signature", "role": "team-a"}))
+	})
+
+	It("should log in with a JWT requested for a ServiceAccount", func() {
+		clientset := kubefake.NewSimpleClientset()
+		var tokenRequest *authenticationv1.TokenRequest
+		clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			tokenRequest = action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+			return true, &authenticationv1.TokenRequest{
+				Status: authenticationv1.TokenRequestStatus{Token: "bound.service.account"},
+			}, nil
+		})
+
+		store.Spec.Vault.Auth.JWT = &smv1alpha1.VaultJWTAuth{
+			Role:              "team-a",
+			ServiceAccountRef: &smmeta.ServiceAccountSelector{Name: "team-a"},
+		}
+		v := newVault()
+		v.resolver = resolver.New(serviceaccount.NewClient(ctrlfake.NewFakeClient(), clientset.CoreV1()), store, "default")
+		Expect(v.setToken(ctx, client)).To(Succeed())
+		Expect(body).To(Equal(map[string]string{"jwt": "bound.service.account", "role": "team-a"}))
+		Expect(tokenRequest.Spec.Audiences).To(Equal([]string{DefaultJWTAudience}))
+	})
+
+	It("should not request a JWT for the Kubernetes API server", func() {
+		store.Spec.Vault.Auth.JWT = &smv1alpha1.VaultJWTAuth{
+			Role: "team-a",
+			ServiceAccountRef: &smmeta.ServiceAccountSelector{
+				Name:      "team-a",
+				Audiences: []string{"https://kubernetes.default.svc"},
+			},
+		}
+		Expect(newVault().setToken(ctx, client)).To(MatchError(ContainSubstring("Kubernetes API server audience")))
+		Expect(request).To(BeNil())
+	})
+
+	It("should log in with a username and password", func() {
+		store.Spec.Vault.Auth.UserPass = &smv1alpha1.VaultUserPassAuth{Username: "team-a", SecretRef: secretRef("password")}
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(request.URL.Path).To(Equal("/v1/auth/userpass/login/team-a"))
+		Expect(body).To(Equal(map[string]string{"password": "hunter2"}))
+
+		store.Spec.Vault.Auth.UserPass = nil
+		store.Spec.Vault.Auth.LDAP = &smv1alpha1.VaultLDAPAuth{Path: "ad", Username: "team-a", SecretRef: secretRef("password")}
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(request.URL.Path).To(Equal("/v1/auth/ad/login/team-a"))
+	})
+
+	It("should log in with a client certificate", func() {
+		certPEM, keyPEM := selfSignedCertificate()
+		secret.Data["tls.crt"] = certPEM
+		secret.Data["tls.key"] = keyPEM
+		store.Spec.Vault.Auth.Cert = &smv1alpha1.VaultCertAuth{
+			Name:       "team-a",
+			ClientCert: secretRef("tls.crt"),
+			SecretRef:  secretRef("tls.key"),
+		}
+		v := newVault()
+		cfg, err := v.newConfig(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig.Certificates).To(HaveLen(1))
+
+		Expect(v.setToken(ctx, client)).To(Succeed())
+		Expect(request.URL.Path).To(Equal("/v1/auth/cert/login"))
+		Expect(body).To(Equal(map[string]string{"name": "team-a"}))
+	})
+
+	It("should log in with a signed sts:GetCallerIdentity request", func() {
+		accessKeyID, secretAccessKey := secretRef("access-key-id"), secretRef("secret-access-key")
+		serverID := "vault.example.com"
+		store.Spec.Vault.Auth.IAM = &smv1alpha1.VaultIAMAuth{
+			Role:     "team-a",
+			ServerID: &serverID,
+			AuthSecretRef: &smv1alpha1.AWSAuth{
+				AccessKeyID:     &accessKeyID,
+				SecretAccessKey: &secretAccessKey,
+			},
+		}
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(request.URL.Path).To(Equal("/v1/auth/aws/login"))
+		Expect(body).To(HaveKeyWithValue("role", "team-a"))
+		Expect(body).To(HaveKeyWithValue("iam_http_request_method", "POST"))
+
+		decode := func(key string) string {
+			out, err := base64.StdEncoding.DecodeString(body[key])
+			Expect(err).NotTo(HaveOccurred())
+			return string(out)
+		}
+		Expect(decode("iam_request_body")).To(ContainSubstring("Action=GetCallerIdentity"))
+		var headers map[string][]string
+		Expect(json.Unmarshal([]byte(decode("iam_request_headers")), &headers)).To(Succeed())
+		Expect(headers).To(HaveKeyWithValue(http.CanonicalHeaderKey(iamServerIDHeader), []string{serverID}))
+		Expect(headers["Authorization"]).To(ConsistOf(ContainSubstring("Credential=AKIDEXAMPLE/")))
+	})
+})
@@ -0,0 +1,289 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store/awsauth"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// tokenCacheKey identifies the tokens of a store. ClusterSecretStores may
+// resolve their credentials per namespace, so the namespace the store is used
+// from is part of the key.
+type tokenCacheKey struct {
+	kind      string
+	name      types.NamespacedName
+	namespace string
+}
+
+// cachedToken is a token issued by Vault when logging in with the auth method
+// of a store.
+type cachedToken struct {
+	token     string
+	renewable bool
+	// expires is zero for tokens without TTL
+	expires time.Time
+	// renewAt is the time the token is renewed or requested again, after two
+	// thirds of its TTL
+	renewAt time.Time
+	// authHash is the hash of the store configuration the token was issued for
+	authHash string
+	// client is used to revoke the token
+	client Client
+}
+
+// valid returns whether the token can be used without renewal.
+func (t *cachedToken) valid(now time.Time) bool {
+	return t.renewAt.IsZero() || now.Before(t.renewAt)
+}
+
+// extend sets the expiry of the token, which is renewed after two thirds of
+// its remaining TTL.
+func (t *cachedToken) extend(ttl time.Duration, now time.Time) {
+	t.expires = now.Add(ttl)
+	t.renewAt = now.Add(ttl * 2 / 3)
+}
+
+// expired returns whether the token is expired.
+func (t *cachedToken) expired(now time.Time) bool {
+	return !t.expires.IsZero() && !now.Before(t.expires)
+}
+
+type tokenCacheEntry struct {
+	// mu serializes logins of a store, so concurrent reconciles share a token
+	mu    sync.Mutex
+	token *cachedToken
+}
+
+// tokenCache caches the tokens issued to stores, so the same token is used
+// for all ExternalSecrets of a store and across syncs.
+type tokenCache struct {
+	mu      sync.Mutex
+	entries map[tokenCacheKey]*tokenCacheEntry
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		entries: make(map[tokenCacheKey]*tokenCacheEntry),
+	}
+}
+
+// lock locks and returns the cache entry of the key. The entry has to be
+// unlocked by the caller.
+func (c *tokenCache) lock(key tokenCacheKey) *tokenCacheEntry {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &tokenCacheEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	return entry
+}
+
+// remove removes and returns the tokens of the store of the given kind and
+// name from the cache.
+func (c *tokenCache) remove(kind string, name types.NamespacedName) []*cachedToken {
+	c.mu.Lock()
+	var entries []*tokenCacheEntry
+	for key, entry := range c.entries {
+		if key.kind == kind && key.name == name {
+			entries = append(entries, entry)
+			delete(c.entries, key)
+		}
+	}
+	c.mu.Unlock()
+
+	var tokens []*cachedToken
+	for _, entry := range entries {
+		entry.mu.Lock()
+		if entry.token != nil {
+			tokens = append(tokens, entry.token)
+		}
+		entry.token = nil
+		entry.mu.Unlock()
+	}
+	return tokens
+}
+
+// authHash returns a hash of the store configuration and of the credentials
+// referenced by its auth method a token is issued for, so tokens are not
+// reused after the configuration changed or the credentials were rotated.
+func (v *Vault) authHash(ctx context.Context) (string, error) {
+	spec := v.store.GetSpec().Vault
+	var credentials []string
+	for _, selector := range spec.Auth.SecretKeySelectors() {
+		value, err := v.resolver.SecretKey(ctx, selector)
+		if err != nil {
+			return "", err
+		}
+		credentials = append(credentials, value)
+	}
+	data, err := json.Marshal(struct {
+		Server      string
+		Namespace   *string
+		CABundle    []byte
+		Auth        smv1alpha1.VaultAuth
+		Credentials []string
+	}{
+		Server:      spec.Server,
+		Namespace:   spec.Namespace,
+		CABundle:    spec.CABundle,
+		Auth:        spec.Auth,
+		Credentials: credentials,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal auth configuration: %w", err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// setCachedToken sets the cached token of the store, if it is still valid or
+// can be renewed, or logs in to request a new token.
+func (v *Vault) setCachedToken(ctx context.Context, client Client) error {
+	hash, err := v.authHash(ctx)
+	if err != nil {
+		return err
+	}
+	entry := v.tokens.lock(v.tokenCacheKey())
+	defer entry.mu.Unlock()
+
+	now := time.Now()
+	if cached := entry.token; cached != nil && cached.authHash == hash {
+		if cached.valid(now) {
+			client.SetToken(cached.token)
+			return nil
+		}
+		if cached.renewable && !cached.expired(now) {
+			err := v.renewToken(ctx, client, cached)
+			if err == nil {
+				return nil
+			}
+			v.log.V(1).Info("unable to renew Vault token, logging in again", "error", err.Error())
+		}
+	}
+
+	token, err := v.login(ctx, client)
+	if err != nil {
+		return err
+	}
+	token.authHash = hash
+	token.client = client
+
+	// expired tokens do not need to be revoked, tokens which may still be
+	// in use by concurrent syncs are only revoked when the store changed.
+	if stale := entry.token; stale != nil && stale.authHash != hash && !stale.expired(now) {
+		if err := revokeToken(ctx, stale); err != nil {
+			v.log.Error(err, "unable to revoke Vault token of previous store configuration")
+		}
+	}
+	entry.token = token
+	client.SetToken(token.token)
+	return nil
+}
+
+// renewToken renews the token and sets it on the client.
+func (v *Vault) renewToken(ctx context.Context, client Client, token *cachedToken) error {
+	client.SetToken(token.token)
+	req := client.NewRequest(http.MethodPost, "/v1/auth/token/renew-self")
+	resp, err := client.RawRequestWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error renewing Vault token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	secret, err := vault.ParseSecret(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to decode JSON payload: %w", err)
+	}
+	now := time.Now()
+	renewed, err := tokenFromSecret(secret, now)
+	if err != nil {
+		return err
+	}
+	if renewed.expires.IsZero() || !now.Add(smv1alpha1.DefaultRenewalLeeway).Before(renewed.expires) {
+		return fmt.Errorf("token TTL cannot be extended beyond the renewal leeway")
+	}
+	token.expires = renewed.expires
+	token.renewAt = renewed.renewAt
+	token.renewable = renewed.renewable
+	return nil
+}
+
+// revokeToken revokes the token with the client it was issued with. The
+// client may be in use by a concurrent sync, so the token is only set on the
+// request.
+func revokeToken(ctx context.Context, token *cachedToken) error {
+	req := token.client.NewRequest(http.MethodPost, "/v1/auth/token/revoke-self")
+	req.ClientToken = token.token
+	resp, err := token.client.RawRequestWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error revoking Vault token: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Release revokes the tokens and forgets the leases and AWS credentials cached
+// for the deleted store.
+func (v *Vault) Release(ctx context.Context, kind string, name types.NamespacedName) error {
+	awsauth.Release(kind, name)
+	if v.leases != nil {
+		// leases are not revoked, as the issued secrets may still be in use
+		v.leases.remove(kind, name)
+	}
+	if v.tokens == nil {
+		return nil
+	}
+	var lastErr error
+	for _, token := range v.tokens.remove(kind, name) {
+		if token.expired(time.Now()) {
+			continue
+		}
+		if err := revokeToken(ctx, token); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (v *Vault) tokenCacheKey() tokenCacheKey {
+	kind := smv1alpha1.SecretStoreKind
+	if _, ok := v.store.(*smv1alpha1.ClusterSecretStore); ok {
+		kind = smv1alpha1.ClusterSecretStoreKind
+	}
+	return tokenCacheKey{
+		kind: kind,
+		name: types.NamespacedName{
+			Namespace: v.store.GetNamespace(),
+			Name:      v.store.GetName(),
+		},
+		namespace: v.namespace,
+	}
+}
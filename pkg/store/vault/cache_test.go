@@ -0,0 +1,212 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	ctxlog "github.com/itscontained/secret-manager/pkg/log"
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
+	"github.com/itscontained/secret-manager/pkg/store/vault/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	loginPath  = "/v1/auth/approle/login"
+	renewPath  = "/v1/auth/token/renew-self"
+	revokePath = "/v1/auth/token/revoke-self"
+)
+
+func tokenResponse(token string, ttl int) *vault.Response {
+	body := fmt.Sprintf(`{"auth":{"client_token":%q,"lease_duration":%d,"renewable":true}}`, token, ttl)
+	return &vault.Response{Response: &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}}
+}
+
+var _ = Describe("Vault token cache", func() {
+	var (
+		ctx      context.Context
+		store    *smv1alpha1.SecretStore
+		kube     ctrlclient.Client
+		client   *fake.Client
+		tokens   *tokenCache
+		requests []string
+		newVault func() *Vault
+	)
+
+	BeforeEach(func() {
+		ctx = ctxlog.IntoContext(context.Background(), ctrllog.NullLogger{})
+		store = &smv1alpha1.SecretStore{
+			ObjectMeta: metav1.ObjectMeta{Name: "vault", Namespace: "default"},
+			Spec: smv1alpha1.SecretStoreSpec{
+				Vault: &smv1alpha1.VaultStore{
+					Server: "https://vault.example.com",
+					Path:   "secret",
+					Auth: smv1alpha1.VaultAuth{
+						AppRole: &smv1alpha1.VaultAppRole{
+							RoleID: "role",
+							SecretRef: smmeta.SecretKeySelector{
+								LocalObjectReference: smmeta.LocalObjectReference{Name: "approle"},
+								Key:                  "secret-id",
+							},
+						},
+					},
+				},
+			},
+		}
+		kube = ctrlfake.NewFakeClient(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "approle", Namespace: "default"},
+			Data:       map[string][]byte{"secret-id": []byte("secret")},
+		})
+
+		logins := 0
+		requests = nil
+		client = fake.NewFakeClient()
+		client.RawRequestFn = func(r *vault.Request) (*vault.Response, error) {
+			requests = append(requests, r.URL.Path+" "+r.ClientToken)
+			switch r.URL.Path {
+			case loginPath:
+				logins++
+				return tokenResponse(fmt.Sprintf("token-%d", logins), 3600), nil
+			case renewPath:
+				return tokenResponse(r.ClientToken, 3600), nil
+			case revokePath:
+				return &vault.Response{Response: &http.Response{
+					StatusCode: http.StatusNoContent,
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}}, nil
+			}
+			return nil, fmt.Errorf("unexpected request to %s", r.URL.Path)
+		}
+
+		tokens = newTokenCache()
+		newVault = func() *Vault {
+			return &Vault{
+				store:     store,
+				namespace: "default",
+				resolver:  resolver.New(kube, store, "default"),
+				log:       ctxlog.FromContext(ctx),
+				tokens:    tokens,
+			}
+		}
+	})
+
+	It("should reuse the token of a store", func() {
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(client.Token()).To(Equal("token-1"))
+		Expect(requests).To(Equal([]string{loginPath + " "}))
+	})
+
+	It("should renew a token after two thirds of its TTL", func() {
+		v := newVault()
+		Expect(v.setToken(ctx, client)).To(Succeed())
+		cached := tokens.entries[v.tokenCacheKey()].token
+		Expect(cached.renewAt).To(BeTemporally("~", time.Now().Add(40*time.Minute), time.Minute))
+		cached.renewAt = time.Now().Add(-time.Second)
+		cached.expires = time.Now().Add(20 * time.Minute)
+
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(client.Token()).To(Equal("token-1"))
+		Expect(requests).To(Equal([]string{loginPath + " ", renewPath + " token-1"}))
+		Expect(cached.valid(time.Now())).To(BeTrue())
+	})
+
+	It("should log in again after the token expired", func() {
+		v := newVault()
+		Expect(v.setToken(ctx, client)).To(Succeed())
+		cached := tokens.entries[v.tokenCacheKey()].token
+		cached.renewAt = time.Now().Add(-time.Minute)
+		cached.expires = time.Now().Add(-time.Second)
+
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(client.Token()).To(Equal("token-2"))
+		Expect(requests).To(HaveLen(2))
+	})
+
+	It("should revoke the token when the store configuration changed", func() {
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		store.Spec.Vault.Auth.AppRole.RoleID = "other-role"
+
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(client.Token()).To(Equal("token-2"))
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[2]).To(Equal(revokePath + " token-1"))
+	})
+
+	It("should log in again when the credentials of the store changed", func() {
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+
+		secret := &corev1.Secret{}
+		Expect(kube.Get(ctx, types.NamespacedName{Namespace: "default", Name: "approle"}, secret)).To(Succeed())
+		secret.Data["secret-id"] = []byte("rotated")
+		Expect(kube.Update(ctx, secret)).To(Succeed())
+
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(client.Token()).To(Equal("token-2"))
+		Expect(requests).To(HaveLen(3))
+		Expect(requests[2]).To(Equal(revokePath + " token-1"))
+	})
+
+	It("should revoke the tokens of a deleted store", func() {
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+
+		name := types.NamespacedName{Namespace: "default", Name: "vault"}
+		Expect(newVault().Release(ctx, smv1alpha1.ClusterSecretStoreKind, name)).To(Succeed())
+		Expect(requests).To(HaveLen(1))
+
+		// the client may be in use by a concurrent sync with another token
+		client.SetToken("in-use")
+		Expect(newVault().Release(ctx, smv1alpha1.SecretStoreKind, name)).To(Succeed())
+		Expect(requests).To(Equal([]string{loginPath + " ", revokePath + " token-1"}))
+		Expect(client.Token()).To(Equal("in-use"), "the token of the client should not be changed")
+		Expect(tokens.entries).To(BeEmpty())
+	})
+
+	It("should not cache tokens read from a secret", func() {
+		store.Spec.Vault.Auth = smv1alpha1.VaultAuth{
+			TokenSecretRef: &smmeta.SecretKeySelector{
+				LocalObjectReference: smmeta.LocalObjectReference{Name: "approle"},
+				Key:                  "secret-id",
+			},
+		}
+		Expect(newVault().setToken(ctx, client)).To(Succeed())
+		Expect(client.Token()).To(Equal("secret"))
+		Expect(requests).To(BeEmpty())
+		Expect(tokens.entries).To(BeEmpty())
+	})
+})
@@ -0,0 +1,314 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// leaseCacheKey identifies a dynamic secret requested for a store.
+type leaseCacheKey struct {
+	store tokenCacheKey
+	// request is the hash of the server and the request issuing the secret
+	request string
+}
+
+// lease is a dynamic secret issued by Vault.
+type lease struct {
+	id        string
+	renewable bool
+	// duration is the lease duration the secret was issued with
+	duration time.Duration
+	expires  time.Time
+	// renewAt is the time the lease is renewed or the secret issued again,
+	// after two thirds of the remaining lease duration
+	renewAt time.Time
+	data    map[string]interface{}
+}
+
+func (l *lease) extend(expires, now time.Time) {
+	l.expires = expires
+	l.renewAt = now.Add(expires.Sub(now) * 2 / 3)
+}
+
+type leaseCacheEntry struct {
+	// mu serializes requests for the same secret, so concurrent reconciles
+	// share a lease
+	mu    sync.Mutex
+	lease *lease
+	// expires is the expiry of lease, guarded by the mutex of the cache
+	expires time.Time
+}
+
+// leaseCache caches dynamic secrets, so the same secret is used across syncs
+// until its lease has to be renewed.
+type leaseCache struct {
+	mu      sync.Mutex
+	entries map[leaseCacheKey]*leaseCacheEntry
+}
+
+func newLeaseCache() *leaseCache {
+	return &leaseCache{
+		entries: make(map[leaseCacheKey]*leaseCacheEntry),
+	}
+}
+
+// lock locks and returns the cache entry of the key. The entry has to be
+// unlocked by the caller. Entries of expired leases are removed.
+func (c *leaseCache) lock(key leaseCacheKey, now time.Time) *leaseCacheEntry {
+	c.mu.Lock()
+	for k, entry := range c.entries {
+		if k != key && !entry.expires.IsZero() && now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &leaseCacheEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	return entry
+}
+
+// set sets the lease of the locked entry. Entries without lease are removed
+// together with the next expired entries.
+func (c *leaseCache) set(entry *leaseCacheEntry, l *lease, now time.Time) {
+	entry.lease = l
+	c.mu.Lock()
+	entry.expires = now
+	if l != nil {
+		entry.expires = l.expires
+	}
+	c.mu.Unlock()
+}
+
+// remove removes the leases of the store of the given kind and name from the
+// cache.
+func (c *leaseCache) remove(kind string, name types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.store.kind == kind && key.store.name == name {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// readDynamicSecret returns the data of a secret issued by a dynamic secrets
+// engine. The secret is reused until two thirds of its lease passed, then the
+// lease is renewed or, if it cannot be renewed, a new secret is issued.
+func (v *Vault) readDynamicSecret(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string]interface{}, error) {
+	if ref.Version != nil {
+		return nil, fmt.Errorf("version is not supported by the %s engine", smv1alpha1.VaultEngineDynamic)
+	}
+	if v.leases == nil {
+		l, err := v.issueSecret(ctx, ref, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		v.setRefreshTime(l)
+		return l.data, nil
+	}
+
+	key, err := v.leaseCacheKey(ref)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	entry := v.leases.lock(key, now)
+	defer entry.mu.Unlock()
+
+	if l := entry.lease; l != nil {
+		if now.Before(l.renewAt) {
+			v.setRefreshTime(l)
+			return l.data, nil
+		}
+		if l.renewable && now.Before(l.expires) {
+			err := v.renewLease(ctx, l, now)
+			if err == nil {
+				v.leases.set(entry, l, now)
+				v.setRefreshTime(l)
+				return l.data, nil
+			}
+			v.log.V(1).Info("unable to renew Vault lease, issuing a new secret", "path", ref.Name, "error", err.Error())
+		}
+	}
+
+	l, err := v.issueSecret(ctx, ref, now)
+	if err != nil {
+		return nil, err
+	}
+	if l.expires.IsZero() {
+		// secrets without expiry are requested again on every sync
+		v.leases.set(entry, nil, now)
+		return l.data, nil
+	}
+	v.leases.set(entry, l, now)
+	v.setRefreshTime(l)
+	return l.data, nil
+}
+
+// issueSecret reads the referenced path of the secrets engine, or writes it
+// with the parameters of the reference.
+func (v *Vault) issueSecret(ctx context.Context, ref smv1alpha1.RemoteReference, now time.Time) (*lease, error) {
+	method := http.MethodGet
+	if len(ref.Parameters) > 0 {
+		method = http.MethodPost
+	}
+	req := v.client.NewRequest(method, fmt.Sprintf("/v1/%s/%s", v.store.GetSpec().Vault.Path, ref.Name))
+	if method == http.MethodPost {
+		if err := req.SetJSONBody(ref.Parameters); err != nil {
+			return nil, fmt.Errorf("error encoding Vault parameters: %s", err.Error())
+		}
+	}
+
+	resp, err := v.client.RawRequestWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := vault.ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("no data returned for %q", ref.Name)
+	}
+	return leaseFromSecret(secret, now), nil
+}
+
+// leaseFromSecret returns the lease of a dynamic secret. Secrets without
+// lease, e.g. certificates issued by the PKI secrets engine, expire at the
+// time of their expiration field, if set.
+func leaseFromSecret(secret *vault.Secret, now time.Time) *lease {
+	l := &lease{
+		id:        secret.LeaseID,
+		renewable: secret.Renewable,
+		duration:  time.Duration(secret.LeaseDuration) * time.Second,
+		data:      secret.Data,
+	}
+	if l.duration > 0 {
+		l.extend(now.Add(l.duration), now)
+		return l
+	}
+	if expiration, ok := unixTime(secret.Data["expiration"]); ok && expiration.After(now) {
+		l.renewable = false
+		l.duration = expiration.Sub(now)
+		l.extend(expiration, now)
+	}
+	return l
+}
+
+// renewLease renews the lease for its original duration. Leases which cannot
+// be extended for at least a third of their original duration, e.g. because
+// they reached their maximum TTL, are not renewed.
+func (v *Vault) renewLease(ctx context.Context, l *lease, now time.Time) error {
+	req := v.client.NewRequest(http.MethodPut, "/v1/sys/leases/renew")
+	err := req.SetJSONBody(map[string]interface{}{
+		"lease_id":  l.id,
+		"increment": int(l.duration.Seconds()),
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding Vault parameters: %s", err.Error())
+	}
+
+	resp, err := v.client.RawRequestWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error renewing Vault lease: %w", err)
+	}
+	defer resp.Body.Close()
+
+	secret, err := vault.ParseSecret(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to decode JSON payload: %w", err)
+	}
+	if secret == nil {
+		return fmt.Errorf("no lease returned")
+	}
+	duration := time.Duration(secret.LeaseDuration) * time.Second
+	if duration < l.duration/3 {
+		return fmt.Errorf("lease cannot be extended beyond %s", duration)
+	}
+	l.renewable = secret.Renewable
+	l.extend(now.Add(duration), now)
+	return nil
+}
+
+// setRefreshTime records the renewal time of the lease, if it is the
+// earliest of the client.
+func (v *Vault) setRefreshTime(l *lease) {
+	if l.renewAt.IsZero() {
+		return
+	}
+	if v.refreshTime.IsZero() || l.renewAt.Before(v.refreshTime) {
+		v.refreshTime = l.renewAt
+	}
+}
+
+func (v *Vault) leaseCacheKey(ref smv1alpha1.RemoteReference) (leaseCacheKey, error) {
+	spec := v.store.GetSpec().Vault
+	data, err := json.Marshal(struct {
+		Server     string
+		Namespace  *string
+		Path       string
+		Name       string
+		Parameters map[string]string
+	}{
+		Server:     spec.Server,
+		Namespace:  spec.Namespace,
+		Path:       spec.Path,
+		Name:       ref.Name,
+		Parameters: ref.Parameters,
+	})
+	if err != nil {
+		return leaseCacheKey{}, fmt.Errorf("unable to marshal request: %w", err)
+	}
+	return leaseCacheKey{
+		store:   v.tokenCacheKey(),
+		request: fmt.Sprintf("%x", sha256.Sum256(data)),
+	}, nil
+}
+
+// unixTime converts a timestamp in seconds since the epoch, as returned by
+// Vault, to a time.
+func unixTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case json.Number:
+		n, err := t.Int64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(n, 0), true
+	case float64:
+		return time.Unix(int64(t), 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,201 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	ctxlog "github.com/itscontained/secret-manager/pkg/log"
+	"github.com/itscontained/secret-manager/pkg/store/vault/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func jsonResponse(body string) *vault.Response {
+	return &vault.Response{Response: &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}}
+}
+
+var _ = Describe("Vault dynamic secrets", func() {
+	var (
+		ctx        context.Context
+		client     *fake.Client
+		leases     *leaseCache
+		requests   []string
+		issueBody  func(n int) string
+		renewBody  string
+		newVault   func() *Vault
+		credsRef   smv1alpha1.RemoteReference
+		issueCount int
+	)
+
+	BeforeEach(func() {
+		ctx = ctxlog.IntoContext(context.Background(), ctrllog.NullLogger{})
+		requests = nil
+		issueCount = 0
+		issueBody = func(n int) string {
+			return fmt.Sprintf(`{"lease_id":"database/creds/readonly/%d","lease_duration":3600,"renewable":true,
+				"data":{"username":"user-%d","password":"secret"}}`, n, n)
+		}
+		renewBody = `{"lease_id":"database/creds/readonly/1","lease_duration":3600,"renewable":true}`
+		credsRef = smv1alpha1.RemoteReference{Name: "creds/readonly"}
+
+		client = fake.NewFakeClient()
+		client.RawRequestFn = func(r *vault.Request) (*vault.Response, error) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch r.URL.Path {
+			case "/v1/database/creds/readonly", "/v1/database/issue/web":
+				issueCount++
+				return jsonResponse(issueBody(issueCount)), nil
+			case "/v1/sys/leases/renew":
+				return jsonResponse(renewBody), nil
+			}
+			return nil, fmt.Errorf("unexpected request to %s", r.URL.Path)
+		}
+
+		leases = newLeaseCache()
+		newVault = func() *Vault {
+			return &Vault{
+				store: &smv1alpha1.SecretStore{
+					ObjectMeta: metav1.ObjectMeta{Name: "vault", Namespace: "default"},
+					Spec: smv1alpha1.SecretStoreSpec{
+						Vault: &smv1alpha1.VaultStore{
+							Server: "https://vault.example.com",
+							Path:   "database",
+							Engine: smv1alpha1.VaultEngineDynamic,
+						},
+					},
+				},
+				namespace: "default",
+				log:       ctxlog.FromContext(ctx),
+				client:    client,
+				leases:    leases,
+			}
+		}
+	})
+
+	cachedLease := func() *lease {
+		for _, entry := range leases.entries {
+			return entry.lease
+		}
+		return nil
+	}
+
+	It("should reuse a secret until its lease has to be renewed", func() {
+		v := newVault()
+		data, err := v.GetSecretMap(ctx, credsRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(map[string][]byte{
+			"username": []byte("user-1"),
+			"password": []byte("secret"),
+		}))
+		Expect(v.RefreshTime()).To(BeTemporally("~", time.Now().Add(40*time.Minute), time.Second))
+
+		v = newVault()
+		property := "username"
+		value, err := v.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "creds/readonly", Property: &property})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(value)).To(Equal("user-1"))
+		Expect(v.RefreshTime()).To(BeTemporally("~", time.Now().Add(40*time.Minute), time.Second))
+		Expect(requests).To(Equal([]string{"GET /v1/database/creds/readonly"}))
+	})
+
+	It("should renew the lease of a secret", func() {
+		_, err := newVault().GetSecretMap(ctx, credsRef)
+		Expect(err).NotTo(HaveOccurred())
+		cachedLease().renewAt = time.Now().Add(-time.Second)
+
+		data, err := newVault().GetSecretMap(ctx, credsRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data["username"])).To(Equal("user-1"))
+		Expect(requests).To(Equal([]string{"GET /v1/database/creds/readonly", "PUT /v1/sys/leases/renew"}))
+		Expect(cachedLease().renewAt).To(BeTemporally("~", time.Now().Add(40*time.Minute), time.Second))
+	})
+
+	It("should issue a new secret if the lease cannot be extended", func() {
+		renewBody = `{"lease_id":"database/creds/readonly/1","lease_duration":60,"renewable":true}`
+		_, err := newVault().GetSecretMap(ctx, credsRef)
+		Expect(err).NotTo(HaveOccurred())
+		cachedLease().renewAt = time.Now().Add(-time.Second)
+
+		data, err := newVault().GetSecretMap(ctx, credsRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data["username"])).To(Equal("user-2"))
+		Expect(requests).To(HaveLen(3))
+	})
+
+	It("should issue a new secret with parameters", func() {
+		issueBody = func(n int) string {
+			return fmt.Sprintf(`{"data":{"certificate":"cert-%d","expiration":%d}}`, n, time.Now().Add(3*time.Hour).Unix())
+		}
+		var body string
+		issue := client.RawRequestFn
+		client.RawRequestFn = func(r *vault.Request) (*vault.Response, error) {
+			body = string(r.BodyBytes)
+			return issue(r)
+		}
+
+		ref := smv1alpha1.RemoteReference{
+			Name:       "issue/web",
+			Parameters: map[string]string{"common_name": "web.example.com"},
+		}
+		v := newVault()
+		data, err := v.GetSecretMap(ctx, ref)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data["certificate"])).To(Equal("cert-1"))
+		Expect(body).To(MatchJSON(`{"common_name":"web.example.com"}`))
+		Expect(v.RefreshTime()).To(BeTemporally("~", time.Now().Add(2*time.Hour), time.Second))
+
+		_, err = newVault().GetSecretMap(ctx, ref)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{"POST /v1/database/issue/web"}))
+	})
+
+	It("should issue secrets without expiry on every read", func() {
+		issueBody = func(n int) string {
+			return fmt.Sprintf(`{"data":{"value":"%d"}}`, n)
+		}
+		v := newVault()
+		_, err := v.GetSecretMap(ctx, credsRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.RefreshTime().IsZero()).To(BeTrue())
+
+		data, err := newVault().GetSecretMap(ctx, credsRef)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data["value"])).To(Equal("2"))
+	})
+
+	It("should reject versions", func() {
+		version := "1"
+		_, err := newVault().GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "creds/readonly", Version: &version})
+		Expect(err).To(MatchError(ContainSubstring("version is not supported")))
+		Expect(requests).To(BeEmpty())
+	})
+})
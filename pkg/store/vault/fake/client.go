@@ -15,7 +15,10 @@ limitations under the License.
 package fake
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/url"
 
 	vault "github.com/hashicorp/vault/api"
 )
@@ -28,7 +31,6 @@ type Client struct {
 
 func NewFakeClient() *Client {
 	return &Client{
-		NewRequestS: new(vault.Request),
 		RawRequestFn: func(r *vault.Request) (*vault.Response, error) {
 			return nil, errors.New("unexpected RawRequest call")
 		},
@@ -47,8 +49,19 @@ func (c *Client) WithRawRequest(resp *vault.Response, err error) *Client {
 	return c
 }
 
+// NewRequest returns the request set with WithNewRequest or a new request
+// for the method and path.
 func (c *Client) NewRequest(method, requestPath string) *vault.Request {
-	return c.NewRequestS
+	if c.NewRequestS != nil {
+		return c.NewRequestS
+	}
+	return &vault.Request{
+		Method:      method,
+		URL:         &url.URL{Path: requestPath},
+		ClientToken: c.token,
+		Params:      make(url.Values),
+		Headers:     make(http.Header),
+	}
 }
 
 func (c *Client) SetToken(v string) {
@@ -63,6 +76,10 @@ func (c *Client) RawRequest(r *vault.Request) (*vault.Response, error) {
 	return c.RawRequestFn(r)
 }
 
+func (c *Client) RawRequestWithContext(ctx context.Context, r *vault.Request) (*vault.Response, error) {
+	return c.RawRequestFn(r)
+}
+
 func (c *Client) Sys() *vault.Sys {
 	return nil
 }
@@ -16,31 +16,60 @@ package vault
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 
 	"github.com/go-logr/logr"
 
 	vault "github.com/hashicorp/vault/api"
 
+	smmeta "github.com/itscontained/secret-manager/pkg/apis/meta/v1"
 	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
 	ctxlog "github.com/itscontained/secret-manager/pkg/log"
 	"github.com/itscontained/secret-manager/pkg/store"
+	"github.com/itscontained/secret-manager/pkg/store/awsauth"
+	"github.com/itscontained/secret-manager/pkg/store/resolver"
 	"github.com/itscontained/secret-manager/pkg/store/schema"
-
-	corev1 "k8s.io/api/core/v1"
-
-	"k8s.io/apimachinery/pkg/types"
+	"github.com/itscontained/secret-manager/pkg/util/property"
 
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ store.Client = &Vault{}
+var _ store.Checker = &Vault{}
+var _ store.Refresher = &Vault{}
+var _ store.Releaser = &Vault{}
+
+// serviceAccountTokenPath is the ServiceAccount token of the controller, used
+// by the Kubernetes auth method if neither a Secret nor a ServiceAccount is
+// referenced.
+var serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+const (
+	// DefaultJWTAudience is the audience of ServiceAccount tokens used with
+	// the JWT and Kubernetes auth methods, if the ServiceAccount reference sets
+	// none.
+	DefaultJWTAudience = "vault"
+
+	// DefaultIAMRegion is the region sts:GetCallerIdentity requests of the
+	// AWS IAM auth method are signed for, which uses the global STS endpoint.
+	DefaultIAMRegion = "us-east-1"
+
+	iamServerIDHeader = "X-Vault-AWS-IAM-Server-ID"
+)
 
 type Client interface {
 	NewRequest(method, requestPath string) *vault.Request
@@ -50,15 +79,21 @@ type Client interface {
 }
 
 type Vault struct {
-	kube      ctrlclient.Client
 	store     smv1alpha1.GenericStore
 	namespace string
+	resolver  *resolver.Resolver
 	log       logr.Logger
 	client    Client
+	tokens    *tokenCache
+	leases    *leaseCache
+
+	// refreshTime is the earliest time a dynamic secret fetched by the client
+	// has to be renewed or issued again
+	refreshTime time.Time
 }
 
 func init() {
-	schema.Register(&Vault{}, &smv1alpha1.SecretStoreSpec{
+	schema.Register(&Vault{tokens: newTokenCache(), leases: newLeaseCache()}, &smv1alpha1.SecretStoreSpec{
 		Vault: &smv1alpha1.VaultStore{},
 	})
 }
@@ -66,13 +101,15 @@ func init() {
 func (v *Vault) New(ctx context.Context, store smv1alpha1.GenericStore, kube ctrlclient.Client, namespace string) (store.Client, error) {
 	log := ctxlog.FromContext(ctx)
 	vClient := &Vault{
-		kube:      kube,
-		namespace: namespace,
 		store:     store,
+		namespace: namespace,
+		resolver:  resolver.New(kube, store, namespace),
 		log:       log,
+		tokens:    v.tokens,
+		leases:    v.leases,
 	}
 
-	cfg, err := vClient.newConfig()
+	cfg, err := vClient.newConfig(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -96,36 +133,57 @@ func (v *Vault) New(ctx context.Context, store smv1alpha1.GenericStore, kube ctr
 }
 
 func (v *Vault) GetSecret(ctx context.Context, ref smv1alpha1.RemoteReference) ([]byte, error) {
-	version := ""
-	if ref.Version != nil {
-		version = *ref.Version
+	data, err := v.read(ctx, ref)
+	if err != nil {
+		return nil, err
 	}
+	return property.Get(data, smmeta.StringValue(ref.Property))
+}
 
-	data, err := v.readSecret(ctx, ref.Name, version)
+func (v *Vault) GetSecretMap(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string][]byte, error) {
+	data, err := v.read(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
-	property := ""
-	if ref.Property != nil {
-		property = *ref.Property
-	}
-	value, exists := data[property]
-	if !exists {
-		return nil, fmt.Errorf("property %q not found in secret response", property)
+
+	return property.Values(data)
+}
+
+// RefreshTime returns the time the earliest dynamic secret fetched by the
+// client has to be renewed or issued again.
+func (v *Vault) RefreshTime() time.Time {
+	return v.refreshTime
+}
+
+// Check verifies the Vault token by looking up its own properties.
+func (v *Vault) Check(ctx context.Context) error {
+	req := v.client.NewRequest(http.MethodGet, "/v1/auth/token/lookup-self")
+	resp, err := v.client.RawRequestWithContext(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error looking up Vault token: %w", err)
 	}
-	return value, nil
+	defer resp.Body.Close()
+	return nil
 }
 
-func (v *Vault) GetSecretMap(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string][]byte, error) {
+// read returns the data of the referenced secret, read from a KV secrets
+// engine or issued by a dynamic secrets engine.
+func (v *Vault) read(ctx context.Context, ref smv1alpha1.RemoteReference) (map[string]interface{}, error) {
+	if v.store.GetSpec().Vault.Engine == smv1alpha1.VaultEngineDynamic {
+		return v.readDynamicSecret(ctx, ref)
+	}
+	if len(ref.Parameters) > 0 {
+		return nil, fmt.Errorf("parameters are not supported by the %s engine", smv1alpha1.VaultEngineKV)
+	}
+
 	version := ""
 	if ref.Version != nil {
 		version = *ref.Version
 	}
-
 	return v.readSecret(ctx, ref.Name, version)
 }
 
-func (v *Vault) readSecret(ctx context.Context, path, version string) (map[string][]byte, error) {
+func (v *Vault) readSecret(ctx context.Context, path, version string) (map[string]interface{}, error) {
 	storeSpec := v.store.GetSpec()
 	kvPath := storeSpec.Vault.Path
 
@@ -167,21 +225,21 @@ func (v *Vault) readSecret(ctx context.Context, path, version string) (map[strin
 		}
 	}
 
-	byteMap := make(map[string][]byte, len(secretData))
-	for k, v := range secretData {
-		str, ok := v.(string)
-		if !ok {
-			return nil, fmt.Errorf("unexpected secret type")
-		}
-		byteMap[k] = []byte(str)
-	}
-
-	return byteMap, nil
+	return secretData, nil
 }
 
-func (v *Vault) newConfig() (*vault.Config, error) {
+func (v *Vault) newConfig(ctx context.Context) (*vault.Config, error) {
 	cfg := vault.DefaultConfig()
 	cfg.Address = v.store.GetSpec().Vault.Server
+	tlsConfig := cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig
+
+	if certAuth := v.store.GetSpec().Vault.Auth.Cert; certAuth != nil {
+		cert, err := v.clientCertificate(ctx, certAuth)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
 
 	certs := v.store.GetSpec().Vault.CABundle
 	if len(certs) == 0 {
@@ -194,15 +252,33 @@ func (v *Vault) newConfig() (*vault.Config, error) {
 		return nil, fmt.Errorf("error loading Vault CA bundle")
 	}
 
-	cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs = caCertPool
+	tlsConfig.RootCAs = caCertPool
 
 	return cfg, nil
 }
 
+// clientCertificate returns the client certificate presented to Vault by the
+// TLS certificate auth method.
+func (v *Vault) clientCertificate(ctx context.Context, certAuth *smv1alpha1.VaultCertAuth) (tls.Certificate, error) {
+	cert, err := v.resolver.SecretKey(ctx, certAuth.ClientCert)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	key, err := v.resolver.SecretKey(ctx, certAuth.SecretRef)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	pair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("error loading Vault client certificate: %s", err.Error())
+	}
+	return pair, nil
+}
+
 func (v *Vault) setToken(ctx context.Context, client Client) error {
 	tokenRef := v.store.GetSpec().Vault.Auth.TokenSecretRef
 	if tokenRef != nil {
-		token, err := v.secretKeyRef(ctx, v.namespace, tokenRef.Name, tokenRef.Key)
+		token, err := v.resolver.SecretKey(ctx, *tokenRef)
 		if err != nil {
 			return err
 		}
@@ -211,58 +287,84 @@ func (v *Vault) setToken(ctx context.Context, client Client) error {
 		return nil
 	}
 
-	appRole := v.store.GetSpec().Vault.Auth.AppRole
-	if appRole != nil {
-		token, err := v.requestTokenWithAppRoleRef(ctx, client, appRole)
-		if err != nil {
-			return err
-		}
-		client.SetToken(token)
-
-		return nil
+	if v.tokens != nil {
+		return v.setCachedToken(ctx, client)
+	}
+	token, err := v.login(ctx, client)
+	if err != nil {
+		return err
 	}
+	client.SetToken(token.token)
+	return nil
+}
+
+// login requests a new token with the auth method of the store.
+func (v *Vault) login(ctx context.Context, client Client) (*cachedToken, error) {
+	auth := v.store.GetSpec().Vault.Auth
 
-	kubernetesAuth := v.store.GetSpec().Vault.Auth.Kubernetes
-	if kubernetesAuth != nil {
-		token, err := v.requestTokenWithKubernetesAuth(ctx, client, kubernetesAuth)
+	var secret *vault.Secret
+	var err error
+	switch {
+	case auth.AppRole != nil:
+		secret, err = v.requestTokenWithAppRoleRef(ctx, client, auth.AppRole)
+	case auth.Kubernetes != nil:
+		secret, err = v.requestTokenWithKubernetesAuth(ctx, client, auth.Kubernetes)
 		if err != nil {
-			return fmt.Errorf("error reading Kubernetes service account token. error: %s", err.Error())
+			err = fmt.Errorf("error reading Kubernetes service account token. error: %s", err.Error())
 		}
-		client.SetToken(token)
-		return nil
+	case auth.JWT != nil:
+		secret, err = v.requestTokenWithJWTAuth(ctx, client, auth.JWT)
+	case auth.Cert != nil:
+		secret, err = v.requestTokenWithCertAuth(ctx, client, auth.Cert)
+	case auth.UserPass != nil:
+		secret, err = v.requestTokenWithPassword(ctx, client, auth.UserPass.Path, smv1alpha1.DefaultVaultUserPassAuthMountPath, auth.UserPass.Username, auth.UserPass.SecretRef)
+	case auth.LDAP != nil:
+		secret, err = v.requestTokenWithPassword(ctx, client, auth.LDAP.Path, smv1alpha1.DefaultVaultLDAPAuthMountPath, auth.LDAP.Username, auth.LDAP.SecretRef)
+	case auth.IAM != nil:
+		secret, err = v.requestTokenWithIAMAuth(ctx, client, auth.IAM)
+	default:
+		return nil, fmt.Errorf("error initializing Vault client: tokenSecretRef, appRole, kubernetes, jwt, cert, userPass, ldap or iam auth not set")
 	}
-
-	return fmt.Errorf("error initializing Vault client: tokenSecretRef, appRoleSecretRef, or Kubernetes auth role not set")
+	if err != nil {
+		return nil, err
+	}
+	return tokenFromSecret(secret, time.Now())
 }
 
-func (v *Vault) secretKeyRef(ctx context.Context, namespace, name, key string) (string, error) {
-	secret := &corev1.Secret{}
-	ref := types.NamespacedName{
-		Namespace: namespace,
-		Name:      name,
+// tokenFromSecret returns the token issued in a login or token renewal
+// response.
+func tokenFromSecret(secret *vault.Secret, now time.Time) (*cachedToken, error) {
+	token, err := secret.TokenID()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token: %s", err.Error())
+	}
+	if token == "" {
+		return nil, errors.New("no token returned")
 	}
-	err := v.kube.Get(ctx, ref, secret)
+	ttl, err := secret.TokenTTL()
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("unable to read token TTL: %s", err.Error())
 	}
-
-	keyBytes, ok := secret.Data[key]
-	if !ok {
-		return "", fmt.Errorf("no data for %q in secret '%s/%s'", key, namespace, name)
+	renewable, err := secret.TokenIsRenewable()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token renewability: %s", err.Error())
 	}
-
-	value := string(keyBytes)
-	valueStr := strings.TrimSpace(value)
-
-	return valueStr, nil
+	cached := &cachedToken{
+		token:     token,
+		renewable: renewable,
+	}
+	if ttl > 0 {
+		cached.extend(ttl, now)
+	}
+	return cached, nil
 }
 
-func (v *Vault) requestTokenWithAppRoleRef(ctx context.Context, client Client, appRole *smv1alpha1.VaultAppRole) (string, error) {
+func (v *Vault) requestTokenWithAppRoleRef(ctx context.Context, client Client, appRole *smv1alpha1.VaultAppRole) (*vault.Secret, error) {
 	roleID := strings.TrimSpace(appRole.RoleID)
 
-	secretID, err := v.secretKeyRef(ctx, v.namespace, appRole.SecretRef.Name, appRole.SecretRef.Key)
+	secretID, err := v.resolver.SecretKey(ctx, appRole.SecretRef)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	parameters := map[string]string{
@@ -280,89 +382,199 @@ func (v *Vault) requestTokenWithAppRoleRef(ctx context.Context, client Client, a
 
 	err = request.SetJSONBody(parameters)
 	if err != nil {
-		return "", fmt.Errorf("error encoding Vault parameters: %s", err.Error())
+		return nil, fmt.Errorf("error encoding Vault parameters: %s", err.Error())
 	}
 
 	resp, err := client.RawRequestWithContext(ctx, request)
 	if err != nil {
-		return "", fmt.Errorf("error logging in to Vault server: %s", err.Error())
+		return nil, fmt.Errorf("error logging in to Vault server: %s", err.Error())
 	}
 
 	defer resp.Body.Close()
 
-	vaultResult := vault.Secret{}
-	if err = resp.DecodeJSON(&vaultResult); err != nil {
-		return "", fmt.Errorf("unable to decode JSON payload: %s", err.Error())
+	vaultResult := &vault.Secret{}
+	if err = resp.DecodeJSON(vaultResult); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON payload: %s", err.Error())
 	}
 
-	token, err := vaultResult.TokenID()
+	return vaultResult, nil
+}
+
+func (v *Vault) requestTokenWithKubernetesAuth(ctx context.Context, client Client, kubernetesAuth *smv1alpha1.VaultKubernetesAuth) (*vault.Secret, error) {
+	var jwt string
+	var err error
+	switch {
+	case kubernetesAuth.ServiceAccountRef != nil:
+		jwt, err = v.serviceAccountToken(ctx, *kubernetesAuth.ServiceAccountRef)
+	case kubernetesAuth.SecretRef != nil:
+		secretRef := *kubernetesAuth.SecretRef
+		if secretRef.Key == "" {
+			secretRef.Key = smv1alpha1.DefaultVaultKubernetesAuthSecretKey
+		}
+		jwt, err = v.resolver.SecretKey(ctx, secretRef)
+	default:
+		jwt, err = readServiceAccountToken(serviceAccountTokenPath)
+	}
 	if err != nil {
-		return "", fmt.Errorf("unable to read token: %s", err.Error())
+		return nil, err
 	}
 
-	if token == "" {
-		return "", errors.New("no token returned")
+	parameters := map[string]string{
+		"role": kubernetesAuth.Role,
+		"jwt":  jwt,
+	}
+	return requestToken(ctx, client, loginURL(kubernetesAuth.Path, smv1alpha1.DefaultVaultKubernetesAuthMountPath), parameters)
+}
+
+// readServiceAccountToken reads the ServiceAccount token mounted into the
+// controller pod.
+func readServiceAccountToken(path string) (string, error) {
+	jwt, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("could not get serviceaccount jwt from disk. error: %s", err)
+	}
+	if len(jwt) == 0 {
+		return "", fmt.Errorf("serviceaccount jwt %q is empty", path)
 	}
+	return string(jwt), nil
+}
 
-	return token, nil
+// serviceAccountToken requests a token for the ServiceAccount to log in to
+// Vault with. The audiences default to DefaultJWTAudience and may not include
+// the Kubernetes API server, as the token is sent to the server of the store.
+func (v *Vault) serviceAccountToken(ctx context.Context, ref smmeta.ServiceAccountSelector) (string, error) {
+	for _, audience := range ref.Audiences {
+		if smv1alpha1.IsKubernetesAPIAudience(audience) {
+			return "", fmt.Errorf("ServiceAccount tokens for Vault may not be issued for the Kubernetes API server audience %q", audience)
+		}
+	}
+	return v.resolver.ServiceAccountToken(ctx, ref, []string{DefaultJWTAudience})
 }
 
-func (v *Vault) requestTokenWithKubernetesAuth(ctx context.Context, client Client, kubernetesAuth *smv1alpha1.VaultKubernetesAuth) (string, error) {
+func (v *Vault) requestTokenWithJWTAuth(ctx context.Context, client Client, jwtAuth *smv1alpha1.VaultJWTAuth) (*vault.Secret, error) {
 	var jwt string
 	var err error
-	if kubernetesAuth.SecretRef == nil {
-		tokenPath := "/var/run/secrets/kubernetes.io/serviceaccount/token"
-		if _, err = os.Stat(tokenPath); !os.IsNotExist(err) {
-			var jwtByte []byte
-			jwtByte, err = ioutil.ReadFile(tokenPath)
-			if err != nil {
-				return "", fmt.Errorf("could not get serviceaccount jwt from disk. error: %s", err)
-			}
-			jwt = string(jwtByte)
-		}
-	} else {
-		key := "token"
-		if kubernetesAuth.SecretRef.Key != "" {
-			key = kubernetesAuth.SecretRef.Key
-		}
-		jwt, err = v.secretKeyRef(ctx, v.namespace, kubernetesAuth.SecretRef.Name, key)
-		if err != nil {
-			return "", err
+	switch {
+	case jwtAuth.ServiceAccountRef != nil:
+		jwt, err = v.serviceAccountToken(ctx, *jwtAuth.ServiceAccountRef)
+	case jwtAuth.SecretRef != nil:
+		jwt, err = v.resolver.SecretKey(ctx, *jwtAuth.SecretRef)
+	default:
+		return nil, errors.New("error reading JWT: serviceAccountRef or secretRef not set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := map[string]string{
+		"jwt": jwt,
+	}
+	if jwtAuth.Role != "" {
+		parameters["role"] = jwtAuth.Role
+	}
+	return requestToken(ctx, client, loginURL(jwtAuth.Path, smv1alpha1.DefaultVaultJWTAuthMountPath), parameters)
+}
+
+// requestTokenWithCertAuth logs in with the client certificate, which is
+// presented in the TLS handshake as configured in newConfig.
+func (v *Vault) requestTokenWithCertAuth(ctx context.Context, client Client, certAuth *smv1alpha1.VaultCertAuth) (*vault.Secret, error) {
+	parameters := map[string]string{}
+	if certAuth.Name != "" {
+		parameters["name"] = certAuth.Name
+	}
+	return requestToken(ctx, client, loginURL(certAuth.Path, smv1alpha1.DefaultVaultCertAuthMountPath), parameters)
+}
+
+// requestTokenWithPassword logs in with the username and password auth
+// methods, e.g. userpass or ldap.
+func (v *Vault) requestTokenWithPassword(ctx context.Context, client Client, authPath, defaultPath, username string, secretRef smmeta.SecretKeySelector) (*vault.Secret, error) {
+	password, err := v.resolver.SecretKey(ctx, secretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := map[string]string{
+		"password": password,
+	}
+	return requestToken(ctx, client, loginURL(authPath, defaultPath, username), parameters)
+}
+
+// requestTokenWithIAMAuth logs in with a signed sts:GetCallerIdentity request,
+// which Vault forwards to AWS to verify the identity of the caller.
+func (v *Vault) requestTokenWithIAMAuth(ctx context.Context, client Client, iamAuth *smv1alpha1.VaultIAMAuth) (*vault.Secret, error) {
+	cfg, err := external.LoadDefaultAWSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading AWS config: %s", err.Error())
+	}
+	if ep := os.Getenv(awsauth.STSEndpoint); ep != "" {
+		cfg.EndpointResolver = aws.ResolveWithEndpointURL(ep)
+	}
+	cfg.Region = DefaultIAMRegion
+	if iamAuth.Region != nil {
+		cfg.Region = *iamAuth.Region
+	}
+	if iamAuth.AuthSecretRef != nil {
+		if err := awsauth.ConfigureAuth(ctx, &cfg, v.store, iamAuth.AuthSecretRef, v.resolver); err != nil {
+			return nil, err
 		}
 	}
 
+	req := sts.New(cfg).GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+	req.SetContext(ctx)
+	if iamAuth.ServerID != nil {
+		req.HTTPRequest.Header.Add(iamServerIDHeader, *iamAuth.ServerID)
+	}
+	if err := req.Sign(); err != nil {
+		return nil, fmt.Errorf("error signing sts:GetCallerIdentity request: %s", err.Error())
+	}
+	headers, err := json.Marshal(req.HTTPRequest.Header)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding sts:GetCallerIdentity headers: %s", err.Error())
+	}
+	body, err := ioutil.ReadAll(req.HTTPRequest.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading sts:GetCallerIdentity body: %s", err.Error())
+	}
+
 	parameters := map[string]string{
-		"role": kubernetesAuth.Role,
-		"jwt":  jwt,
+		"role":                    iamAuth.Role,
+		"iam_http_request_method": req.HTTPRequest.Method,
+		"iam_request_url":         base64.StdEncoding.EncodeToString([]byte(req.HTTPRequest.URL.String())),
+		"iam_request_headers":     base64.StdEncoding.EncodeToString(headers),
+		"iam_request_body":        base64.StdEncoding.EncodeToString(body),
 	}
-	authPath := kubernetesAuth.Path
+	return requestToken(ctx, client, loginURL(iamAuth.Path, smv1alpha1.DefaultVaultIAMAuthMountPath), parameters)
+}
+
+// loginURL returns the login URL of the auth backend mounted at authPath, or
+// at defaultPath if not set.
+func loginURL(authPath, defaultPath string, elems ...string) string {
 	if authPath == "" {
-		authPath = smv1alpha1.DefaultVaultKubernetesAuthMountPath
+		authPath = defaultPath
 	}
-	url := strings.Join([]string{"/v1", "auth", authPath, "login"}, "/")
+	return strings.Join(append([]string{"/v1", "auth", authPath, "login"}, elems...), "/")
+}
+
+// requestToken logs in to Vault with the parameters of the auth method.
+func requestToken(ctx context.Context, client Client, url string, parameters map[string]string) (*vault.Secret, error) {
 	request := client.NewRequest("POST", url)
 
-	err = request.SetJSONBody(parameters)
+	err := request.SetJSONBody(parameters)
 	if err != nil {
-		return "", fmt.Errorf("error encoding Vault parameters: %s", err.Error())
+		return nil, fmt.Errorf("error encoding Vault parameters: %s", err.Error())
 	}
 
 	resp, err := client.RawRequestWithContext(ctx, request)
 	if err != nil {
-		return "", fmt.Errorf("error calling Vault server: %s", err.Error())
+		return nil, fmt.Errorf("error logging in to Vault server: %s", err.Error())
 	}
 
 	defer resp.Body.Close()
-	vaultResult := vault.Secret{}
-	err = resp.DecodeJSON(&vaultResult)
-	if err != nil {
-		return "", fmt.Errorf("unable to decode JSON payload: %s", err.Error())
-	}
 
-	token, err := vaultResult.TokenID()
-	if err != nil {
-		return "", fmt.Errorf("unable to read token: %s", err.Error())
+	vaultResult := &vault.Secret{}
+	if err = resp.DecodeJSON(vaultResult); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON payload: %s", err.Error())
 	}
 
-	return token, nil
+	return vaultResult, nil
 }
@@ -0,0 +1,103 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	vault "github.com/hashicorp/vault/api"
+
+	smv1alpha1 "github.com/itscontained/secret-manager/pkg/apis/secretmanager/v1alpha1"
+	"github.com/itscontained/secret-manager/pkg/store/vault/fake"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const kvResponse = `{
+  "data": {
+    "data": {
+      "password": "foo-123",
+      "port": 5432,
+      "ratio": 0.5,
+      "enabled": true,
+      "empty": null,
+      "db": {"primary": {"host": "db.example.com", "port": 5432}},
+      "hosts": ["a.example.com", "b.example.com"]
+    }
+  }
+}`
+
+var _ = Describe("Vault KV secrets", func() {
+	var (
+		ctx context.Context
+		v   *Vault
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		client := fake.NewFakeClient()
+		client.RawRequestFn = func(r *vault.Request) (*vault.Response, error) {
+			Expect(r.URL.Path).To(Equal("/v1/secret/data/teamA/hello-service"))
+			return &vault.Response{Response: &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(strings.NewReader(kvResponse)),
+			}}, nil
+		}
+		v = &Vault{
+			store: &smv1alpha1.SecretStore{
+				ObjectMeta: metav1.ObjectMeta{Name: "vault", Namespace: "default"},
+				Spec: smv1alpha1.SecretStoreSpec{
+					Vault: &smv1alpha1.VaultStore{
+						Server: "https://vault.example.com",
+						Path:   "secret",
+					},
+				},
+			},
+			client: client,
+		}
+	})
+
+	It("should convert non-string values", func() {
+		data, err := v.GetSecretMap(ctx, smv1alpha1.RemoteReference{Name: "teamA/hello-service"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data).To(Equal(map[string][]byte{
+			"password": []byte("foo-123"),
+			"port":     []byte("5432"),
+			"ratio":    []byte("0.5"),
+			"enabled":  []byte("true"),
+			"empty":    []byte{},
+			"db":       []byte(`{"primary":{"host":"db.example.com","port":5432}}`),
+			"hosts":    []byte(`["a.example.com","b.example.com"]`),
+		}))
+	})
+
+	It("should fetch nested properties", func() {
+		property := "db.primary.port"
+		data, err := v.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "teamA/hello-service", Property: &property})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("5432"))
+
+		property = "enabled"
+		data, err = v.GetSecret(ctx, smv1alpha1.RemoteReference{Name: "teamA/hello-service", Property: &property})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("true"))
+	})
+})
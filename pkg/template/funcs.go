@@ -0,0 +1,164 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"golang.org/x/crypto/pkcs12"
+
+	"sigs.k8s.io/yaml"
+)
+
+// FuncMap returns the functions available to templates. Functions take the
+// value to transform as last argument so they can be used in pipelines.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"b64enc": b64enc,
+		"b64dec": b64dec,
+
+		"toJson":   toJSON,
+		"fromJson": fromJSON,
+		"toYaml":   toYAML,
+
+		"indent":  indent,
+		"nindent": nindent,
+		"upper":   strings.ToUpper,
+		"lower":   strings.ToLower,
+		"trim":    strings.TrimSpace,
+
+		"pkcs12key":      pkcs12key,
+		"pkcs12keyPass":  pkcs12keyPass,
+		"pkcs12cert":     pkcs12cert,
+		"pkcs12certPass": pkcs12certPass,
+		"filterPEM":      filterPEM,
+	}
+}
+
+func b64enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func b64dec(s string) (string, error) {
+	out, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("unable to decode base64: %w", err)
+	}
+	return string(out), nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal json: %w", err)
+	}
+	return string(out), nil
+}
+
+func fromJSON(s string) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal json: %w", err)
+	}
+	return v, nil
+}
+
+func toYAML(v interface{}) (string, error) {
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal yaml: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func nindent(spaces int, s string) string {
+	return "\n" + indent(spaces, s)
+}
+
+func pkcs12key(s string) (string, error) {
+	return pkcs12keyPass("", s)
+}
+
+// pkcs12keyPass returns the private key of a PKCS#12 archive as PKCS#8 PEM
+// block.
+func pkcs12keyPass(password, s string) (string, error) {
+	key, _, err := pkcs12.Decode([]byte(s), password)
+	if err != nil {
+		return "", fmt.Errorf("unable to decode pkcs12 archive: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal private key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+func pkcs12cert(s string) (string, error) {
+	return pkcs12certPass("", s)
+}
+
+// pkcs12certPass returns all certificates of a PKCS#12 archive as PEM blocks.
+func pkcs12certPass(password, s string) (string, error) {
+	blocks, err := pkcs12.ToPEM([]byte(s), password)
+	if err != nil {
+		return "", fmt.Errorf("unable to decode pkcs12 archive: %w", err)
+	}
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		// drop the bag attributes added by ToPEM
+		if err := pem.Encode(&buf, &pem.Block{Type: block.Type, Bytes: block.Bytes}); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
+
+// filterPEM returns the PEM blocks of the given type, e.g. CERTIFICATE.
+func filterPEM(pemType, s string) (string, error) {
+	var buf bytes.Buffer
+	rest := []byte(s)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != pemType {
+			continue
+		}
+		if err := pem.Encode(&buf, block); err != nil {
+			return "", err
+		}
+	}
+	if buf.Len() == 0 {
+		return "", fmt.Errorf("no PEM block of type %q found", pemType)
+	}
+	return buf.String(), nil
+}
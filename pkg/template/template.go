@@ -0,0 +1,84 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Execute renders the values of the data, stringData, labels and annotations
+// of the secret template as Go templates, with the secret data fetched from the
+// store as input. The rendered stringData is moved into data, as stringData is
+// a write-only field.
+func Execute(tpl *corev1.Secret, data map[string][]byte) error {
+	values := make(map[string]string, len(data))
+	for k, v := range data {
+		values[k] = string(v)
+	}
+
+	rendered := make(map[string][]byte, len(tpl.Data)+len(tpl.StringData))
+	for k, v := range tpl.Data {
+		out, err := render("data."+k, string(v), values)
+		if err != nil {
+			return err
+		}
+		rendered[k] = out
+	}
+	for k, v := range tpl.StringData {
+		out, err := render("stringData."+k, v, values)
+		if err != nil {
+			return err
+		}
+		rendered[k] = out
+	}
+	tpl.Data = rendered
+	tpl.StringData = nil
+
+	if err := renderMap("metadata.labels", tpl.Labels, values); err != nil {
+		return err
+	}
+	return renderMap("metadata.annotations", tpl.Annotations, values)
+}
+
+func renderMap(name string, m map[string]string, values map[string]string) error {
+	for k, v := range m {
+		out, err := render(name+"."+k, v, values)
+		if err != nil {
+			return err
+		}
+		m[k] = string(out)
+	}
+	return nil
+}
+
+func render(name, text string, values map[string]string) ([]byte, error) {
+	t, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(FuncMap()).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse template %q: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, values); err != nil {
+		return nil, fmt.Errorf("unable to execute template %q: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
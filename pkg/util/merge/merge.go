@@ -20,3 +20,15 @@ func Merge(src, dst map[string][]byte) map[string][]byte {
 	}
 	return src
 }
+
+// MergeStringMap merges dst into src, overwriting existing keys. A nil src
+// map is allocated.
+func MergeStringMap(src, dst map[string]string) map[string]string {
+	if src == nil {
+		src = make(map[string]string, len(dst))
+	}
+	for k, v := range dst {
+		src[k] = v
+	}
+	return src
+}
@@ -0,0 +1,107 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package property extracts values from JSON secret data with dotted paths,
+// e.g. "db.primary.password" or "hosts.0".
+package property
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Get returns the value at the given path of the secret data. A key matching
+// the whole path takes precedence over a nested lookup, so keys containing
+// dots can be addressed as before. Dots in nested keys can be escaped with a
+// backslash, numeric path elements index into lists.
+func Get(data map[string]interface{}, path string) ([]byte, error) {
+	if v, ok := data[path]; ok {
+		return Value(v)
+	}
+
+	var node interface{} = data
+	for _, key := range split(path) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			v, ok := n[key]
+			if !ok {
+				return nil, notFound(path)
+			}
+			node = v
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, notFound(path)
+			}
+			node = n[i]
+		default:
+			return nil, notFound(path)
+		}
+	}
+	return Value(node)
+}
+
+// Value returns strings as-is, null as empty value and all other values,
+// i.e. numbers, booleans, objects and lists, as JSON.
+func Value(v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case string:
+		return []byte(t), nil
+	case nil:
+		return []byte{}, nil
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal secret value: %w", err)
+	}
+	return out, nil
+}
+
+// Values converts every value of the secret data with Value.
+func Values(data map[string]interface{}) (map[string][]byte, error) {
+	out := make(map[string][]byte, len(data))
+	for k, v := range data {
+		value, err := Value(v)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		out[k] = value
+	}
+	return out, nil
+}
+
+// split splits the path at unescaped dots.
+func split(path string) []string {
+	var keys []string
+	var key strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			key.WriteByte('.')
+			i++
+		case path[i] == '.':
+			keys = append(keys, key.String())
+			key.Reset()
+		default:
+			key.WriteByte(path[i])
+		}
+	}
+	return append(keys, key.String())
+}
+
+func notFound(path string) error {
+	return fmt.Errorf("property %q not found in secret response", path)
+}
@@ -0,0 +1,83 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package property
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Property", func() {
+	var data map[string]interface{}
+
+	BeforeEach(func() {
+		dec := json.NewDecoder(strings.NewReader(`{
+			"username": "admin",
+			"tls.crt": "certificate",
+			"port": 5432,
+			"enabled": true,
+			"empty": null,
+			"db": {"primary": {"password": "s3cr3t", "port": 5433}, "a.b": "escaped"},
+			"hosts": ["db-0", {"name": "db-1"}]
+		}`))
+		dec.UseNumber()
+		Expect(dec.Decode(&data)).To(Succeed())
+	})
+
+	DescribeTable("should return the value at the path",
+		func(path, expected string) {
+			value, err := Get(data, path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(value)).To(Equal(expected))
+		},
+		Entry("top-level string", "username", "admin"),
+		Entry("top-level key containing a dot", "tls.crt", "certificate"),
+		Entry("number", "port", "5432"),
+		Entry("boolean", "enabled", "true"),
+		Entry("null", "empty", ""),
+		Entry("nested string", "db.primary.password", "s3cr3t"),
+		Entry("nested number", "db.primary.port", "5433"),
+		Entry("escaped dot", `db.a\.b`, "escaped"),
+		Entry("object as JSON", "db.primary", `{"password":"s3cr3t","port":5433}`),
+		Entry("list index", "hosts.0", "db-0"),
+		Entry("list element field", "hosts.1.name", "db-1"),
+		Entry("list as JSON", "hosts", `["db-0",{"name":"db-1"}]`),
+	)
+
+	DescribeTable("should fail on missing paths",
+		func(path string) {
+			_, err := Get(data, path)
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+		},
+		Entry("empty path", ""),
+		Entry("missing key", "password"),
+		Entry("missing nested key", "db.replica.password"),
+		Entry("index out of range", "hosts.2"),
+		Entry("non-numeric index", "hosts.first"),
+		Entry("path into a scalar", "username.first"),
+	)
+
+	It("should convert all values", func() {
+		values, err := Values(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(values).To(HaveLen(len(data)))
+		Expect(string(values["port"])).To(Equal("5432"))
+		Expect(string(values["db"])).To(Equal(`{"a.b":"escaped","primary":{"password":"s3cr3t","port":5433}}`))
+	})
+})
@@ -0,0 +1,74 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultExpirationSeconds is the requested lifetime of ServiceAccount tokens.
+const DefaultExpirationSeconds int64 = 600
+
+// Client is a Kubernetes client which can additionally request ServiceAccount
+// tokens, which is not supported by the controller-runtime client.
+type Client interface {
+	ctrlclient.Client
+	corev1client.ServiceAccountsGetter
+}
+
+type client struct {
+	ctrlclient.Client
+	corev1client.ServiceAccountsGetter
+}
+
+// NewClient returns a Client using the given ServiceAccountsGetter, usually
+// a CoreV1 clientset, for token requests.
+func NewClient(kube ctrlclient.Client, serviceAccounts corev1client.ServiceAccountsGetter) Client {
+	return &client{
+		Client:                kube,
+		ServiceAccountsGetter: serviceAccounts,
+	}
+}
+
+// Token requests a token with the given audiences for a ServiceAccount using
+// the TokenRequest API. The kube client must implement Client.
+func Token(ctx context.Context, kube ctrlclient.Client, ref types.NamespacedName, audiences []string) (string, error) {
+	c, ok := kube.(Client)
+	if !ok {
+		return "", fmt.Errorf("kubernetes client does not support requesting ServiceAccount tokens")
+	}
+	expiration := DefaultExpirationSeconds
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expiration,
+		},
+	}
+	resp, err := c.ServiceAccounts(ref.Namespace).CreateToken(ctx, ref.Name, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to request token for ServiceAccount %q: %w", ref, err)
+	}
+	return resp.Status.Token, nil
+}
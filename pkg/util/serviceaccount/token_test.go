@@ -0,0 +1,62 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceaccount
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Token", func() {
+	ref := types.NamespacedName{Namespace: "default", Name: "secret-reader"}
+
+	It("should request a token for the ServiceAccount", func() {
+		clientset := fake.NewSimpleClientset()
+		var request *authenticationv1.TokenRequest
+		clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			create := action.(k8stesting.CreateAction)
+			Expect(action.GetSubresource()).To(Equal("token"))
+			Expect(action.GetNamespace()).To(Equal("default"))
+			request = create.GetObject().(*authenticationv1.TokenRequest)
+			return true, &authenticationv1.TokenRequest{
+				Status: authenticationv1.TokenRequestStatus{Token: "token"},
+			}, nil
+		})
+
+		kube := NewClient(ctrlfake.NewFakeClient(), clientset.CoreV1())
+		token, err := Token(context.Background(), kube, ref, []string{"sts.amazonaws.com"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(Equal("token"))
+		Expect(request.Spec.Audiences).To(ConsistOf("sts.amazonaws.com"))
+		Expect(*request.Spec.ExpirationSeconds).To(Equal(DefaultExpirationSeconds))
+	})
+
+	It("should fail if the client cannot request tokens", func() {
+		_, err := Token(context.Background(), ctrlfake.NewFakeClient(), ref, nil)
+		Expect(err).To(MatchError(ContainSubstring("does not support requesting ServiceAccount tokens")))
+	})
+})